@@ -0,0 +1,222 @@
+// Package archive parses the pages of the MySQL Reference Manual inside the
+// HTML archives Oracle publishes, e.g. refman-5.7-en.html-chapter.tar.gz,
+// without extracting them to disk.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sjmudd/mysql-variables-parser/parser"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// Pages are the base names of the members parsed, in the order they are
+// merged so the system variables page takes precedence
+var Pages = []string{
+	"server-system-variables.html",
+	"innodb-parameters.html",
+	"replication-options*.html",
+	"server-status-variables.html",
+}
+
+// the version in the name of an archive
+var versionRE = regexp.MustCompile(`refman-([0-9]+\.[0-9]+)`)
+
+// Member is a page read from an archive
+type Member struct {
+	Name string // the path in the archive
+	Data []byte
+}
+
+// IsArchive reports whether the file is an archive judging by its name
+func IsArchive(filename string) bool {
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(strings.ToLower(filename), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// the position of the member's page in Pages, -1 if it isn't parsed
+func pageOrder(name string) int {
+	for i, pattern := range Pages {
+		if matched, _ := path.Match(pattern, path.Base(name)); matched {
+			return i
+		}
+	}
+	return -1
+}
+
+// Members returns the members of the archive which are parsed, in the
+// order of Pages then by name
+func Members(filename string) ([]Member, error) {
+	var members []Member
+	add := func(name string, r io.Reader) error {
+		if pageOrder(name) < 0 {
+			return nil
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("reading %s: %v", name, err)
+		}
+		members = append(members, Member{Name: name, Data: data})
+		return nil
+	}
+
+	var err error
+	if strings.HasSuffix(strings.ToLower(filename), ".zip") {
+		err = readZip(filename, add)
+	} else {
+		err = readTar(filename, add)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if a, b := pageOrder(members[i].Name), pageOrder(members[j].Name); a != b {
+			return a < b
+		}
+		return members[i].Name < members[j].Name
+	})
+	return members, nil
+}
+
+// call add for each file in the zip archive
+func readZip(filename string, add func(name string, r io.Reader) error) error {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("reading %s: %v", f.Name, err)
+		}
+		err = add(f.Name, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call add for each file in the tar archive, which may be gzipped
+func readTar(filename string, add func(name string, r io.Reader) error) error {
+	fi, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fi.Close()
+
+	var r io.Reader = fi
+	lower := strings.ToLower(filename)
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(fi)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := add(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// the SHA-256 of the file
+func checksum(filename string) (string, error) {
+	fi, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer fi.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fi); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Parse parses the pages of the archive into a single table. Each row
+// records the member it came from as its source. platform selects the
+// values used where they differ, as for parser.Parser.SetPlatform. The
+// provenance of the table is that of the archive as a whole. The report
+// cross-checks the summary and details tables of all the members and
+// lists the conflicting values.
+func Parse(filename, tablename, platform string) (*table.Table, []parser.Finding, error) {
+	members, err := Members(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil, fmt.Errorf("%s has none of the pages: %s", filename, strings.Join(Pages, ", "))
+	}
+
+	sum, err := checksum(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := table.NewTable(tablename)
+	manualVersion := ""
+	var contents []parser.Contents
+	for _, m := range members {
+		var p parser.Parser
+		p.SetPlatform(platform)
+		p.SetSource(m.Name)
+		if err := p.ProcessReader(bytes.NewReader(m.Data), tablename); err != nil {
+			return nil, nil, fmt.Errorf("parsing %s: %v", m.Name, err)
+		}
+		if t.Version() == "" {
+			t.SetVersion(p.Table().Version())
+			manualVersion = t.Version()
+		}
+		t.AppendTable(p.Table())
+		contents = append(contents, p.Contents())
+	}
+	if t.Version() == "" {
+		if m := versionRE.FindStringSubmatch(path.Base(filename)); m != nil {
+			t.SetVersion(m[1])
+		}
+	}
+	t.SetProvenance(table.Provenance{
+		Source:        filename,
+		SHA256:        sum,
+		ManualVersion: manualVersion,
+		Parsed:        time.Now().UTC(),
+	})
+	return t, append(parser.Report(contents...), parser.ConflictFindings(t.Conflicts())...), nil
+}
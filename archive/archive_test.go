@@ -0,0 +1,149 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// a details table row of the form "<label></strong>...<td>value"
+func detail(label, value string) string {
+	return `<tr><td scope="row"><span class="bold"><strong>` + label + `</strong></span></td><td colspan="2">` + value + `</td></tr>`
+}
+
+// a details table row whose value is code
+func codeDetail(label, value string) string {
+	return `<tr><td scope="row"><span class="bold"><strong>` + label + `</strong></span></td><td colspan="2"><code class="literal">` + value + `</code></td></tr>`
+}
+
+var pages = map[string]string{
+	"refman-5.7-en.html-chapter/server-system-variables.html": `<html><head><title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.5 Server System Variables</title></head><body>
+<table summary="System Variable Summary"><tbody>
+<tr><td>max_connections</td><td>Yes</td><td>Yes</td><td>Yes</td><td>Global</td><td>Yes</td></tr>
+</tbody></table>
+<table summary="Options for max_connections" border="1"><tbody>` + codeDetail("Type", "Integer") + codeDetail("Default Value", "151") + `</tbody></table>
+</body></html>`,
+
+	"refman-5.7-en.html-chapter/innodb-parameters.html": `<html><head><title>MySQL :: MySQL 5.7 Reference Manual :: 14.15 InnoDB Startup Options and System Variables</title></head><body>
+<table summary="Options for innodb_buffer_pool_size" border="1"><tbody>` +
+		detail("Variable Scope", "Global") + detail("Dynamic Variable", "Yes") + codeDetail("Type", "Integer") + codeDetail("Default Value", "134217728") +
+		`</tbody></table>
+</body></html>`,
+
+	"refman-5.7-en.html-chapter/server-status-variables.html": `<html><head><title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.9 Server Status Variables</title></head><body>
+<table summary="Status Variable Summary"><thead><tr><th>Variable Name</th><th>Variable Type</th><th>Variable Scope</th></tr></thead><tbody>
+<tr><td>Aborted_clients</td><td>Integer</td><td>Global</td></tr>
+</tbody></table>
+</body></html>`,
+
+	"refman-5.7-en.html-chapter/index.html": `<html><body>not parsed</body></html>`,
+}
+
+func writeTarGz(t *testing.T, filename string) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+	for name, page := range pages {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(page)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(page))
+	}
+	tw.Close()
+	gz.Close()
+	if err := os.WriteFile(filename, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, filename string) {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, page := range pages {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(page))
+	}
+	zw.Close()
+	if err := os.WriteFile(filename, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	tgz := filepath.Join(dir, "refman-5.7-en.html-chapter.tar.gz")
+	writeTarGz(t, tgz)
+	zipped := filepath.Join(dir, "refman-5.7-en.html-chapter.zip")
+	writeZip(t, zipped)
+
+	for _, filename := range []string{tgz, zipped} {
+		if !IsArchive(filename) {
+			t.Errorf("IsArchive(%s) = false", filename)
+		}
+		tbl, _, err := Parse(filename, "sysvar57", "")
+		if err != nil {
+			t.Fatalf("Parse(%s) failed: %v", filename, err)
+		}
+		if tbl.Version() != "5.7" || tbl.Rows() != 3 {
+			t.Errorf("Parse(%s) = version %q with %d rows, want 5.7 with 3", filename, tbl.Version(), tbl.Rows())
+		}
+		if p, found := tbl.Provenance(); !found || p.Source != filename || len(p.SHA256) != 64 || p.Rows != 3 {
+			t.Errorf("Parse(%s) provenance = %+v, want the archive's with 3 rows", filename, p)
+		}
+
+		tests := []struct {
+			name, member, dataType, defaultValue string
+			systemVar, dynamic                   bool
+		}{
+			{"max_connections", "server-system-variables.html", "Integer", "151", true, true},
+			{"innodb_buffer_pool_size", "innodb-parameters.html", "Integer", "134217728", true, true},
+			{"Aborted_clients", "server-status-variables.html", "Integer", "", false, false},
+		}
+		for _, test := range tests {
+			row, found := tbl.Lookup(test.name)
+			if !found {
+				t.Errorf("%s: %s not found", filename, test.name)
+				continue
+			}
+			rec := row.Record()
+			if rec.Source != "refman-5.7-en.html-chapter/"+test.member || rec.DataType != test.dataType ||
+				rec.DefaultValue != test.defaultValue || rec.SystemVar != test.systemVar ||
+				(rec.Dynamic != nil && *rec.Dynamic) != test.dynamic {
+				t.Errorf("%s: %s = %+v, want from %s", filename, test.name, rec, test.member)
+			}
+		}
+	}
+}
+
+func TestMembers(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "refman.tar.gz")
+	writeTarGz(t, filename)
+	members, err := Members(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range members {
+		names = append(names, filepath.Base(m.Name))
+	}
+	want := []string{"server-system-variables.html", "innodb-parameters.html", "server-status-variables.html"}
+	if len(names) != len(want) {
+		t.Fatalf("Members() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Members() = %v, want %v", names, want)
+		}
+	}
+
+	if _, _, err := Parse(filepath.Join(t.TempDir(), "missing.zip"), "t", ""); err == nil {
+		t.Error("Parse() of a missing archive succeeded")
+	}
+}
@@ -0,0 +1,160 @@
+// Package config reads MySQL option files (my.cnf) and the output of
+// SHOW VARIABLES so they can be compared with the documented variables.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Option is a single setting from an option file
+type Option struct {
+	Section string
+	Name    string
+	Value   string
+	Line    int
+}
+
+// the sections read by mysqld
+var serverSections = map[string]bool{
+	"mysqld": true,
+	"server": true,
+}
+
+// IsServer returns true if the section is read by the server. Versioned
+// sections like [mysqld-5.7] are included.
+func (o Option) IsServer() bool {
+	section := o.Section
+	if i := strings.Index(section, "-"); i >= 0 {
+		section = section[:i]
+	}
+	return serverSections[section]
+}
+
+// remove matching quotes around a value and replace the escapes as mysqld
+// does. An unknown escape keeps its backslash.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 's':
+			b.WriteByte(' ')
+		case '"', '\'', '\\':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// ParseOptionFile reads an option file returning the options in the order found.
+// The loose- prefix is removed from names. !include directives are ignored.
+func ParseOptionFile(r io.Reader) ([]Option, error) {
+	var (
+		options []Option
+		section string
+		lineNo  int
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' || line[0] == '!' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: invalid section header: %s", lineNo, line)
+			}
+			section = strings.ToLower(strings.TrimSpace(line[1:end]))
+			continue
+		}
+		if section == "" {
+			return nil, fmt.Errorf("line %d: option outside of a section: %s", lineNo, line)
+		}
+		o := Option{Section: section, Line: lineNo}
+		if i := strings.Index(line, "="); i >= 0 {
+			o.Name = strings.TrimSpace(line[:i])
+			o.Value = strings.TrimSpace(line[i+1:])
+			if len(o.Value) > 0 && o.Value[0] != '"' && o.Value[0] != '\'' {
+				if j := strings.Index(o.Value, " #"); j >= 0 {
+					o.Value = strings.TrimSpace(o.Value[:j])
+				}
+			}
+			o.Value = unquote(o.Value)
+		} else {
+			o.Name = line
+		}
+		o.Name = strings.TrimPrefix(o.Name, "loose-")
+		o.Name = strings.TrimPrefix(o.Name, "loose_")
+		options = append(options, o)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// ParseVariables reads SHOW VARIABLES output returning a map of variable
+// name to value. Both the tab separated batch output (mysql -B) and the
+// boxed table output of the mysql client are understood.
+func ParseVariables(r io.Reader) (map[string]string, error) {
+	variables := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var name, value string
+		switch {
+		case strings.HasPrefix(line, "+"):
+			continue
+		case strings.HasPrefix(line, "|"):
+			fields := strings.Split(strings.Trim(line, "|"), "|")
+			if len(fields) < 2 {
+				continue
+			}
+			name = strings.TrimSpace(fields[0])
+			value = strings.TrimSpace(strings.Join(fields[1:], "|"))
+		default:
+			fields := strings.SplitN(line, "\t", 2)
+			name = strings.TrimSpace(fields[0])
+			if len(fields) > 1 {
+				value = fields[1]
+			}
+		}
+		if name == "" || name == "Variable_name" {
+			continue
+		}
+		variables[strings.ToLower(name)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return variables, nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The sections of mysqld-auto.cnf holding the variables set with SET PERSIST
+// and SET PERSIST_ONLY. In the version 1 format the static section is inside
+// the dynamic one.
+const (
+	PersistedDynamic = "mysql_server"
+	PersistedStatic  = "mysql_server_static_options"
+)
+
+// the sections of the version 2 format used by later 8.0 releases, and
+// whether they hold static variables
+var persistedSections = map[string]bool{
+	PersistedDynamic:                      false,
+	PersistedStatic:                       true,
+	"mysql_dynamic_variables":             false,
+	"mysql_dynamic_parse_early_variables": false,
+	"mysql_sensitive_dynamic_variables":   false,
+	"mysql_static_variables":              true,
+}
+
+// Persisted is a variable from mysqld-auto.cnf
+type Persisted struct {
+	Section   string
+	Static    bool // true if in a section of variables only applied at startup
+	Name      string
+	Value     string
+	User      string
+	Host      string
+	Timestamp int64
+}
+
+// the entry of a variable in mysqld-auto.cnf
+type persistedEntry struct {
+	Value    string
+	Metadata struct {
+		Timestamp int64
+		User      string
+		Host      string
+	}
+}
+
+// ParsePersisted reads mysqld-auto.cnf returning the variables sorted by
+// section and name
+func ParsePersisted(r io.Reader) ([]Persisted, error) {
+	var file map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("reading mysqld-auto.cnf: %v", err)
+	}
+
+	var variables []Persisted
+	var section func(name string, raw json.RawMessage) error
+	section = func(name string, raw json.RawMessage) error {
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return fmt.Errorf("reading section %s: %v", name, err)
+		}
+		for key, value := range entries {
+			if _, found := persistedSections[key]; found {
+				if err := section(key, value); err != nil {
+					return err
+				}
+				continue
+			}
+			var entry persistedEntry
+			if err := json.Unmarshal(value, &entry); err != nil {
+				return fmt.Errorf("reading %s in section %s: %v", key, name, err)
+			}
+			variables = append(variables, Persisted{
+				Section:   name,
+				Static:    persistedSections[name],
+				Name:      key,
+				Value:     entry.Value,
+				User:      entry.Metadata.User,
+				Host:      entry.Metadata.Host,
+				Timestamp: entry.Metadata.Timestamp,
+			})
+		}
+		return nil
+	}
+	for name, raw := range file {
+		if _, found := persistedSections[name]; !found {
+			continue
+		}
+		if err := section(name, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(variables, func(i, j int) bool {
+		if variables[i].Section != variables[j].Section {
+			return variables[i].Section < variables[j].Section
+		}
+		return variables[i].Name < variables[j].Name
+	})
+	return variables, nil
+}
+
+// the escapes understood in option file values
+var optionEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\t", "\\t",
+	"\b", "\\b",
+)
+
+// quote a value for an option file if needed
+func quoteOption(value string) string {
+	value = optionEscaper.Replace(value)
+	if value != "" && !strings.ContainsAny(value, " #;'\"") {
+		return value
+	}
+	if !strings.Contains(value, `"`) {
+		return `"` + value + `"`
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	// with both quotes the double quotes are escaped
+	return `"` + strings.Replace(value, `"`, `\"`, -1) + `"`
+}
+
+// WriteOptionFile writes the options in option file form, starting a new
+// section each time the section changes
+func WriteOptionFile(w io.Writer, options []Option) error {
+	section := ""
+	for i, o := range options {
+		if i == 0 || o.Section != section {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			section = o.Section
+			if _, err := fmt.Fprintf(w, "[%s]\n", section); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", o.Name, quoteOption(o.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
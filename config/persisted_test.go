@@ -0,0 +1,68 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteOptionFile(t *testing.T) {
+	options := []Option{
+		{Section: "mysqld", Name: "max_connections", Value: "500"},
+		{Section: "mysqld", Name: "init_connect", Value: "SET NAMES utf8mb4"},
+		{Section: "mysqld", Name: "ft_boolean_syntax", Value: `+ -><()~*:""&|`},
+		{Section: "mysqld", Name: "datadir", Value: `C:\data`},
+		{Section: "mysqld", Name: "init_file", Value: `a"b'c`},
+		{Section: "client", Name: "port", Value: "3306"},
+	}
+	want := `[mysqld]
+max_connections=500
+init_connect="SET NAMES utf8mb4"
+ft_boolean_syntax='+ -><()~*:""&|'
+datadir=C:\\data
+init_file="a\"b'c"
+
+[client]
+port=3306
+`
+	var b bytes.Buffer
+	if err := WriteOptionFile(&b, options); err != nil {
+		t.Fatalf("WriteOptionFile() failed: %v", err)
+	}
+	if b.String() != want {
+		t.Errorf("WriteOptionFile() = %s, want %s", b.String(), want)
+	}
+}
+
+func TestOptionFileRoundTrip(t *testing.T) {
+	values := []string{
+		"500",
+		"SET NAMES utf8mb4",
+		`+ -><()~*:""&|`,
+		`a"b'c`,
+		`it's`,
+		`C:\data`,
+		"a\tb\nc",
+		`ends with \`,
+		"",
+	}
+	var options []Option
+	for _, v := range values {
+		options = append(options, Option{Section: "mysqld", Name: "init_connect", Value: v})
+	}
+	var b bytes.Buffer
+	if err := WriteOptionFile(&b, options); err != nil {
+		t.Fatalf("WriteOptionFile() failed: %v", err)
+	}
+	got, err := ParseOptionFile(&b)
+	if err != nil {
+		t.Fatalf("ParseOptionFile() failed: %v", err)
+	}
+	if len(got) != len(values) {
+		t.Fatalf("ParseOptionFile() returned %d options, want %d", len(got), len(values))
+	}
+	for i, v := range values {
+		if got[i].Value != v {
+			t.Errorf("the value %q was read back as %q", v, got[i].Value)
+		}
+	}
+}
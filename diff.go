@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sjmudd/mysql-variables-parser/diff"
+)
+
+// show the differences between the variables of two versions
+func diffCommand(args []string) int {
+	flags := newFlagSet("diff", "", "Show the variables added, removed or changed between two versions.")
+	from := flags.String("from", "", "The page documenting the older version")
+	to := flags.String("to", "", "The page documenting the newer version")
+	format := flags.String("format", "text", "Output format: text or json")
+	outputFile := flags.String("output", "", "File to write the differences to instead of stdout")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	switch {
+	case flags.NArg() > 0:
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	case *from == "" || *to == "":
+		return usageError(flags, "--from and --to are needed")
+	case *format != "text" && *format != "json":
+		return usageError(flags, "unknown format '%s', expected text or json", *format)
+	case *page.version != "":
+		return usageError(flags, "--version can't be used as there are two versions")
+	}
+
+	tables, err := page.parsePages([]string{*from, *to})
+	if err != nil {
+		return failure("Failed to read the pages: %v", err)
+	}
+	changes := diff.Tables(tables[0], tables[1])
+	err = writeTo(*outputFile, func(w io.Writer) error {
+		if *format == "json" {
+			if changes == nil {
+				changes = []diff.Change{}
+			}
+			b, err := json.MarshalIndent(changes, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s\n", b)
+			return err
+		}
+		diff.Print(w, changes)
+		return nil
+	})
+	if err != nil {
+		return failure("Failed to write the differences: %v", err)
+	}
+	if len(changes) > 0 {
+		return exitProblems
+	}
+	return exitOK
+}
@@ -0,0 +1,121 @@
+// Package diff compares the system variables documented for two MySQL versions
+package diff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// Kind is the kind of change to a variable
+type Kind string
+
+// The kinds of change
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Field is an attribute of a variable which differs between the versions
+type Field struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Change describes how a variable differs between the versions
+type Change struct {
+	Variable string  `json:"variable"`
+	Kind     Kind    `json:"kind"`
+	Fields   []Field `json:"fields,omitempty"`
+}
+
+// the attributes compared, named as the fields of table.Record
+var fields = []struct {
+	name  string
+	value func(rec table.Record) string
+}{
+	{"cmd_line", func(rec table.Record) string { return strconv.FormatBool(rec.CmdLine) }},
+	{"option_file", func(rec table.Record) string { return strconv.FormatBool(rec.OptionFile) }},
+	{"system_var", func(rec table.Record) string { return strconv.FormatBool(rec.SystemVar) }},
+	{"scope", func(rec table.Record) string { return rec.Scope }},
+	{"dynamic", func(rec table.Record) string {
+		if rec.Dynamic == nil {
+			return ""
+		}
+		return strconv.FormatBool(*rec.Dynamic)
+	}},
+	{"command_line_format", func(rec table.Record) string { return rec.CommandLineFormat }},
+	{"default_value", func(rec table.Record) string { return rec.DefaultValue }},
+	{"data_type", func(rec table.Record) string { return rec.DataType }},
+	{"min_value", func(rec table.Record) string { return rec.MinValue }},
+	{"max_value", func(rec table.Record) string { return rec.MaxValue }},
+	{"valid_values", func(rec table.Record) string { return strings.Join(rec.ValidValues, ",") }},
+}
+
+// index the records by canonical name as the documented form of a name can
+// differ between versions
+func byName(t *table.Table) map[string]table.Record {
+	records := make(map[string]table.Record)
+	for _, rec := range t.Records() {
+		name := table.CanonicalName(rec.Name)
+		if _, found := records[name]; !found {
+			records[name] = rec
+		}
+	}
+	return records
+}
+
+// Tables returns the changes from the variables of one table to those of
+// another, sorted by name
+func Tables(from, to *table.Table) []Change {
+	before, after := byName(from), byName(to)
+
+	var changes []Change
+	for name, old := range before {
+		rec, found := after[name]
+		if !found {
+			changes = append(changes, Change{Variable: name, Kind: Removed})
+			continue
+		}
+		var differences []Field
+		for _, f := range fields {
+			if a, b := f.value(old), f.value(rec); a != b {
+				differences = append(differences, Field{Name: f.name, From: a, To: b})
+			}
+		}
+		if len(differences) > 0 {
+			changes = append(changes, Change{Variable: name, Kind: Changed, Fields: differences})
+		}
+	}
+	for name := range after {
+		if _, found := before[name]; !found {
+			changes = append(changes, Change{Variable: name, Kind: Added})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Variable < changes[j].Variable })
+	return changes
+}
+
+// Print writes the changes one per line: + for an added variable, - for a
+// removed one and ~ for a changed one, followed by the fields which differ
+func Print(w io.Writer, changes []Change) {
+	for _, c := range changes {
+		switch c.Kind {
+		case Added:
+			fmt.Fprintln(w, "+", c.Variable)
+		case Removed:
+			fmt.Fprintln(w, "-", c.Variable)
+		default:
+			fmt.Fprintln(w, "~", c.Variable)
+			for _, f := range c.Fields {
+				fmt.Fprintf(w, "    %s: %q -> %q\n", f.Name, f.From, f.To)
+			}
+		}
+	}
+}
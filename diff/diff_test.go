@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func newTable(version string, rows ...table.Row) *table.Table {
+	t := table.NewTable("sysvar")
+	t.SetVersion(version)
+	for _, r := range rows {
+		t.AppendRow(r)
+	}
+	return t
+}
+
+func newRow(name, dynamic, defaultValue string) table.Row {
+	var r table.Row
+	r.SetSystemVariableName(name)
+	r.SetSystemVar("Yes")
+	r.SetDynamic(dynamic)
+	r.SetDefaultValue(defaultValue)
+	return r
+}
+
+func TestTables(t *testing.T) {
+	from := newTable("5.6",
+		newRow("big-tables", "Yes", "OFF"),
+		newRow("query_cache_size", "Yes", "1048576"),
+		newRow("innodb_log_file_size", "No", "50331648"))
+	got := Tables(from, newTable("5.7",
+		newRow("big_tables", "Yes", "OFF"),
+		newRow("innodb_log_file_size", "No", "50331648"),
+		newRow("max_execution_time", "Yes", "0")))
+	want := []Change{
+		{Variable: "max_execution_time", Kind: Added},
+		{Variable: "query_cache_size", Kind: Removed},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tables() = %+v, want %+v", got, want)
+	}
+
+	got = Tables(from, newTable("8.0", newRow("big_tables", "No", "OFF")))
+	if len(got) != 3 || got[0].Kind != Changed ||
+		!reflect.DeepEqual(got[0].Fields, []Field{{Name: "dynamic", From: "true", To: "false"}}) {
+		t.Errorf("Tables() = %+v, want big_tables changed to not dynamic", got)
+	}
+
+	var b bytes.Buffer
+	Print(&b, got[:1])
+	if want := "~ big_tables\n    dynamic: \"true\" -> \"false\"\n"; b.String() != want {
+		t.Errorf("Print() = %q, want %q", b.String(), want)
+	}
+}
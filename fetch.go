@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/sjmudd/mysql-variables-parser/fetch"
+)
+
+// fetch a page of the manual through the cache
+func fetchCommand(args []string) int {
+	flags := newFlagSet("fetch", "", "Fetch a page of the MySQL Reference Manual, keeping it in a cache directory per version,\nand write it to stdout or --output.")
+	version := flags.String("version", "", "The MySQL version of the manual, e.g. 5.7")
+	page := flags.String("page", fetch.DefaultPage, "The page of the manual")
+	cacheDir := flags.String("cache-dir", "", "The cache directory, by default mysql-variables-parser in the user's cache directory")
+	offline := flags.Bool("offline", false, "Only read the cache")
+	baseURL := flags.String("base-url", fetch.DefaultBaseURL, "Where the manual of each version is found")
+	outputFile := flags.String("output", "", "File to write the page to instead of stdout")
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	switch {
+	case flags.NArg() > 0:
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	case *version == "":
+		return usageError(flags, "--version is needed")
+	}
+	if *cacheDir == "" {
+		dir, err := fetch.DefaultCacheDir()
+		if err != nil {
+			return usageError(flags, "--cache-dir is needed as there is no cache directory: %v", err)
+		}
+		*cacheDir = dir
+	}
+
+	f := &fetch.Fetcher{BaseURL: *baseURL, CacheDir: *cacheDir, Offline: *offline}
+	path, err := f.Fetch(*version, *page)
+	if err != nil {
+		return failure("Failed to fetch %s: %v", f.URL(*version, *page), err)
+	}
+	fi, err := os.Open(path)
+	if err != nil {
+		return failure("Failed to read the cached page: %v", err)
+	}
+	defer fi.Close()
+	if err := writeTo(*outputFile, func(w io.Writer) error {
+		_, err := io.Copy(w, fi)
+		return err
+	}); err != nil {
+		return failure("Failed to write the page: %v", err)
+	}
+	return exitOK
+}
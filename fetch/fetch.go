@@ -0,0 +1,207 @@
+// Package fetch downloads pages of the MySQL Reference Manual keeping them
+// in an on-disk cache. Cached pages are revalidated with a conditional GET
+// and checked against their checksum before use.
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// DefaultBaseURL is where the manual of each version is found
+const DefaultBaseURL = "https://dev.mysql.com/doc/refman"
+
+// DefaultPage is the page documenting the system variables
+const DefaultPage = "server-system-variables"
+
+// the names accepted, which are also used in the cache's paths
+var (
+	versionRE = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+	pageRE    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+)
+
+// DefaultTimeout limits how long a page may take to download when the
+// Fetcher has no Client of its own
+const DefaultTimeout = 2 * time.Minute
+
+// the client used if the Fetcher has none
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// ErrNotCached is returned in offline mode for a page which isn't cached
+var ErrNotCached = errors.New("the page is not in the cache")
+
+// Metadata describes a cached page. It is stored next to the page.
+type Metadata struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	SHA256       string    `json:"sha256"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// Fetcher fetches pages into the cache
+type Fetcher struct {
+	BaseURL  string       // of the manual, DefaultBaseURL if empty
+	CacheDir string       // holding a directory per version
+	Offline  bool         // only read the cache
+	Client   *http.Client // a client with DefaultTimeout if nil
+}
+
+// DefaultCacheDir returns the cache directory used if none is given
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mysql-variables-parser"), nil
+}
+
+// URL returns the address of the page of the manual of the version
+func (f *Fetcher) URL(version, page string) string {
+	base := f.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	return fmt.Sprintf("%s/%s/en/%s.html", base, version, page)
+}
+
+// the paths of the cached page and its metadata
+func (f *Fetcher) paths(version, page string) (string, string) {
+	dir := filepath.Join(f.CacheDir, version)
+	return filepath.Join(dir, page+".html"), filepath.Join(dir, page+".json")
+}
+
+// checksum returns the SHA-256 of the file
+func checksum(filename string) (string, error) {
+	fi, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer fi.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fi); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cached returns the metadata of the cached page. found is false if it
+// isn't cached or the page doesn't match its checksum.
+func (f *Fetcher) cached(version, page string) (md Metadata, found bool) {
+	pagePath, mdPath := f.paths(version, page)
+	b, err := os.ReadFile(mdPath)
+	if err != nil || json.Unmarshal(b, &md) != nil {
+		return Metadata{}, false
+	}
+	if sum, err := checksum(pagePath); err != nil || sum != md.SHA256 {
+		return Metadata{}, false
+	}
+	return md, true
+}
+
+// Fetch returns the path of the cached page of the manual of the version,
+// downloading it if it's not cached or has changed. In offline mode only
+// the cache is read.
+func (f *Fetcher) Fetch(version, page string) (string, error) {
+	if !versionRE.MatchString(version) {
+		return "", fmt.Errorf("invalid MySQL version '%s', expected e.g. 5.7", version)
+	}
+	if !pageRE.MatchString(page) {
+		return "", fmt.Errorf("invalid page name '%s'", page)
+	}
+	pagePath, mdPath := f.paths(version, page)
+	md, found := f.cached(version, page)
+	if f.Offline {
+		if !found {
+			return "", fmt.Errorf("%s %s: %w", version, page, ErrNotCached)
+		}
+		return pagePath, nil
+	}
+
+	url := f.URL(version, page)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		if md.ETag != "" {
+			req.Header.Set("If-None-Match", md.ETag)
+		}
+		if md.LastModified != "" {
+			req.Header.Set("If-Modified-Since", md.LastModified)
+		}
+	}
+	client := f.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && found:
+		md.Fetched = time.Now().UTC()
+		return pagePath, writeMetadata(mdPath, md)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(pagePath), 0o755); err != nil {
+		return "", err
+	}
+	sum, err := writePage(pagePath, resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %v", url, err)
+	}
+	return pagePath, writeMetadata(mdPath, Metadata{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		SHA256:       sum,
+		Fetched:      time.Now().UTC(),
+	})
+}
+
+// write the page through a temporary file so an interrupted download
+// doesn't replace the cached page, returning its checksum
+func writePage(filename string, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".fetch-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// write the metadata of a cached page
+func writeMetadata(filename string, md Metadata) error {
+	b, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(b, '\n'), 0o644)
+}
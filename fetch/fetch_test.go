@@ -0,0 +1,127 @@
+package fetch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// a stand-in for the manual counting the pages sent in full
+type manual struct {
+	page string
+	sent int
+}
+
+func (m *manual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/5.7/en/server-system-variables.html" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("ETag", `"v1"`)
+	w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	if r.Header.Get("If-None-Match") == `"v1"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	m.sent++
+	w.Write([]byte(m.page))
+}
+
+func TestFetch(t *testing.T) {
+	m := &manual{page: "<html>5.7</html>"}
+	ts := httptest.NewServer(m)
+	defer ts.Close()
+	f := &Fetcher{BaseURL: ts.URL, CacheDir: t.TempDir()}
+
+	read := func() string {
+		t.Helper()
+		path, err := f.Fetch("5.7", DefaultPage)
+		if err != nil {
+			t.Fatalf("Fetch() failed: %v", err)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	if got := read(); got != m.page || m.sent != 1 {
+		t.Fatalf("Fetch() = %q after %d downloads, want %q after 1", got, m.sent, m.page)
+	}
+	if got := read(); got != m.page || m.sent != 1 {
+		t.Errorf("Fetch() = %q after %d downloads, want the cached page revalidated", got, m.sent)
+	}
+
+	// a page which doesn't match its checksum is downloaded again
+	path := filepath.Join(f.CacheDir, "5.7", DefaultPage+".html")
+	if err := os.WriteFile(path, []byte("corrupt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != m.page || m.sent != 2 {
+		t.Errorf("Fetch() = %q after %d downloads, want the corrupt page replaced", got, m.sent)
+	}
+
+	if _, err := f.Fetch("8.0", DefaultPage); err == nil {
+		t.Error("Fetch() of a missing page succeeded")
+	}
+	if _, err := f.Fetch("../5.7", DefaultPage); err == nil {
+		t.Error("Fetch() accepted an invalid version")
+	}
+}
+
+func TestOffline(t *testing.T) {
+	m := &manual{page: "<html>5.7</html>"}
+	ts := httptest.NewServer(m)
+	f := &Fetcher{BaseURL: ts.URL, CacheDir: t.TempDir(), Offline: true}
+
+	if _, err := f.Fetch("5.7", DefaultPage); !errors.Is(err, ErrNotCached) {
+		t.Errorf("offline Fetch() of an uncached page = %v, want ErrNotCached", err)
+	}
+	f.Offline = false
+	if _, err := f.Fetch("5.7", DefaultPage); err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	ts.Close()
+
+	f.Offline = true
+	if _, err := f.Fetch("5.7", DefaultPage); err != nil {
+		t.Errorf("offline Fetch() of a cached page failed: %v", err)
+	}
+}
+
+func TestURL(t *testing.T) {
+	var f Fetcher
+	want := "https://dev.mysql.com/doc/refman/8.0/en/server-system-variables.html"
+	if got := f.URL("8.0", DefaultPage); got != want {
+		t.Errorf("URL() = %s, want %s", got, want)
+	}
+}
+
+// a server which stops sending the page doesn't block the fetch for ever
+func TestTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>"))
+		w.(http.Flusher).Flush()
+		<-stop
+	}))
+	defer ts.Close()
+	defer close(stop)
+
+	saved := defaultClient
+	defer func() { defaultClient = saved }()
+	defaultClient = &http.Client{Timeout: 100 * time.Millisecond}
+
+	f := &Fetcher{BaseURL: ts.URL, CacheDir: t.TempDir()}
+	if _, err := f.Fetch("5.7", DefaultPage); err == nil {
+		t.Error("Fetch() of a page which never ends succeeded")
+	}
+	if DefaultTimeout <= 0 || saved.Timeout != DefaultTimeout {
+		t.Errorf("the default client has the timeout %v, want DefaultTimeout", saved.Timeout)
+	}
+}
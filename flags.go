@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/archive"
+	"github.com/sjmudd/mysql-variables-parser/parser"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// the page parsed if none is given
+const defaultInput = "server-system-variables.html"
+
+// buildVersion is the version of the program, set when it's built with
+// -ldflags "-X main.buildVersion=v1.2.3"
+var buildVersion string
+
+// toolVersion returns the version of the program recorded in the provenance
+func toolVersion() string {
+	if buildVersion != "" {
+		return buildVersion
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// newFlagSet returns the flag set of a command whose --help shows the
+// arguments and summary of the command followed by its flags
+func newFlagSet(name, arguments, summary string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		w := flags.Output()
+		fmt.Fprintf(w, "Usage: %s\n\n%s\n\nFlags:\n", strings.TrimSpace(program()+" "+name+" [flags] "+arguments), summary)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the arguments of a command. If the command should stop,
+// after --help or a bad flag, ok is false and rc is the exit code.
+func parseFlags(flags *flag.FlagSet, args []string) (rc int, ok bool) {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK, false
+	}
+	if err != nil {
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// usageError reports a mistake on the command line followed by the usage
+// of the command, returning the exit code
+func usageError(flags *flag.FlagSet, format string, args ...interface{}) int {
+	fmt.Fprintf(flags.Output(), format+"\n", args...)
+	flags.Usage()
+	return exitUsage
+}
+
+// failure reports why a command failed returning the exit code
+func failure(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	return exitFailure
+}
+
+// stringList is a flag which can be given several times
+type stringList []string
+
+// String returns the values separated by commas
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set adds a value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// pageFlags are the flags of the commands reading a page
+type pageFlags struct {
+	table     *string
+	version   *string
+	platform  *string
+	source    *string
+	verbose   *bool
+	logLevel  *logLevel
+	logFormat *logFormat
+}
+
+// addPageFlags adds the flags describing how a page is parsed
+func addPageFlags(flags *flag.FlagSet) pageFlags {
+	p := pageFlags{
+		table:    flags.String("table", "sysvars", "Name of the table holding the variables"),
+		version:  flags.String("version", "", "The MySQL version documented, if it can't be found in the page title"),
+		platform: flags.String("platform", "", "The platform whose values are used where they differ: unix (the default) or windows. Values given for another platform are dropped"),
+		source:   flags.String("source", "", "Where the page came from, e.g. its URL, recorded in the provenance. By default the file parsed"),
+		verbose:  flags.Bool("verbose", false, "Log the parser's state changes and the values found, the same as --log-level=debug"),
+	}
+	level := logLevel(slog.LevelInfo)
+	format := logFormat("text")
+	p.logLevel, p.logFormat = &level, &format
+	flags.Var(p.logLevel, "log-level", "The least severe diagnostics logged to stderr: trace, which logs every token, debug, info (the default), warn or error")
+	flags.Var(p.logFormat, "log-format", "How the diagnostics are logged: text or json")
+	flags.StringVar(p.version, "mysql-version", "", "Alias of --version")
+	return p
+}
+
+// setupLogging makes the logger configured by the flags the default
+func (p pageFlags) setupLogging() *slog.Logger {
+	level := slog.Level(*p.logLevel)
+	if *p.verbose && level > slog.LevelDebug {
+		level = slog.LevelDebug
+	}
+	logger := newLogger(level, *p.logFormat)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// record where the page came from and the program which parsed it
+func (p pageFlags) setProvenance(t *table.Table, filename string) {
+	provenance, _ := t.Provenance()
+	provenance.Source = filename
+	if filename == "-" {
+		provenance.Source = "stdin"
+	}
+	if *p.source != "" {
+		provenance.Source = *p.source
+	}
+	provenance.ToolVersion = toolVersion()
+	t.SetProvenance(provenance)
+}
+
+// parsePage parses the page in filename, or stdin if it is "-". The pages
+// of an archive of the manual are parsed into a single table.
+func (p pageFlags) parsePage(filename string) (*table.Table, error) {
+	t, _, err := p.parsePageReport(filename)
+	return t, err
+}
+
+// parsePageReport parses the page as parsePage also returning the report
+// cross-checking its summary and details tables and listing the conflicts
+func (p pageFlags) parsePageReport(filename string) (*table.Table, []parser.Finding, error) {
+	logger := p.setupLogging()
+	if archive.IsArchive(filename) {
+		t, findings, err := archive.Parse(filename, *p.table, *p.platform)
+		if err != nil {
+			return nil, nil, err
+		}
+		if *p.version != "" {
+			t.SetVersion(*p.version)
+		}
+		p.setProvenance(t, filename)
+		return t, findings, nil
+	}
+
+	var r io.Reader = os.Stdin
+	if filename != "-" {
+		fi, err := os.Open(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer fi.Close()
+		r = fi
+	}
+
+	var c parser.Parser
+	c.SetLogger(logger)
+	c.SetPlatform(*p.platform)
+	c.SetFilename(filename)
+	if err := c.ProcessReader(r, *p.table); err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %v", filename, err)
+	}
+	if *p.version != "" {
+		c.Table().SetVersion(*p.version)
+	}
+	p.setProvenance(c.Table(), filename)
+
+	findings := append(parser.Report(c.Contents()), parser.ConflictFindings(c.Table().Conflicts())...)
+	return c.Table(), findings, nil
+}
+
+// parsePages parses each of the files. A version or source given on the
+// command line only applies to a single file.
+func (p pageFlags) parsePages(filenames []string) ([]*table.Table, error) {
+	single := p
+	if len(filenames) > 1 {
+		none := ""
+		single.version = &none
+		single.source = &none
+	}
+	tables := make([]*table.Table, 0, len(filenames))
+	for _, filename := range filenames {
+		t, err := single.parsePage(filename)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// write to the named file, or stdout if there is no name
+func writeTo(filename string, write func(w io.Writer) error) error {
+	if filename == "" {
+		return write(os.Stdout)
+	}
+	fo, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := write(fo); err != nil {
+		fo.Close()
+		return err
+	}
+	return fo.Close()
+}
@@ -0,0 +1,38 @@
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// a header line with the field names followed by a line per variable.
+// Unknown values are empty and the valid values are separated by commas.
+func writeCSV(w io.Writer, t *table.Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.RecordFields); err != nil {
+		return err
+	}
+	for _, rec := range t.Records() {
+		values := fieldValues(rec)
+		line := make([]string, 0, len(values))
+		for _, v := range values {
+			switch v := v.(type) {
+			case nil:
+				line = append(line, "")
+			case []string:
+				line = append(line, strings.Join(v, ","))
+			default:
+				line = append(line, fmt.Sprint(v))
+			}
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,70 @@
+// Package format writes a table of system variables in one of the
+// supported output formats.
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// Writer writes the table to w in a given format
+type Writer func(w io.Writer, t *table.Table) error
+
+var writers = map[string]Writer{
+	"sql":    writeSQL,
+	"json":   writeJSON,
+	"ndjson": writeNDJSON,
+	"yaml":   writeYAML,
+	"csv":    writeCSV,
+}
+
+// Names returns the names of the supported formats
+func Names() []string {
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the writer of the named format
+func Lookup(name string) (Writer, error) {
+	if w, found := writers[strings.ToLower(name)]; found {
+		return w, nil
+	}
+	return nil, fmt.Errorf("unknown format '%s', expected one of: %s", name, strings.Join(Names(), ", "))
+}
+
+// the SQL statements to create and fill the table
+func writeSQL(w io.Writer, t *table.Table) error {
+	return t.Dump(w)
+}
+
+// fieldValues returns the values of the record in the order of table.RecordFields
+func fieldValues(rec table.Record) []interface{} {
+	var dynamic interface{}
+	if rec.Dynamic != nil {
+		dynamic = *rec.Dynamic
+	}
+	return []interface{}{
+		rec.Name,
+		rec.CmdLine,
+		rec.OptionFile,
+		rec.SystemVar,
+		rec.Scope,
+		dynamic,
+		rec.CommandLineFormat,
+		rec.DefaultValue,
+		rec.DataType,
+		rec.MinValue,
+		rec.MaxValue,
+		rec.ValidValues,
+		rec.Description,
+		rec.Source,
+	}
+}
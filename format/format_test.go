@@ -0,0 +1,216 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func testTable() *table.Table {
+	t := table.NewTable("sysvar57")
+
+	var r table.Row
+	r.SetSystemVariableName("ft_boolean_syntax")
+	r.SetCmdLine("Yes")
+	r.SetOptionFile("Yes")
+	r.SetSystemVar("Yes")
+	r.SetVarScope("Global")
+	r.SetDynamic("Yes")
+	r.SetDataType("string")
+	r.SetDefaultValue(`+ -><()~*:""&|`)
+	r.SetDescription("The list of operators supported by boolean full-text searches.")
+	r.SetSource("refman-5.7/server-system-variables.html")
+	t.AppendRow(r)
+
+	r = table.Row{}
+	r.SetSystemVariableName("big-tables")
+	r.SetCmdLine("Yes")
+	r.SetOptionFile("Yes")
+	r.SetSystemVar(" ")
+	r.SetVarScope(" ")
+	r.SetDynamic(" ")
+	r.SetValidValues([]string{"ON", "OFF"})
+	t.AppendRow(r)
+
+	return t
+}
+
+func write(t *testing.T, name string) string {
+	w, err := Lookup(name)
+	if err != nil {
+		t.Fatalf("Lookup(%s) failed: %v", name, err)
+	}
+	var b bytes.Buffer
+	if err := w(&b, testTable()); err != nil {
+		t.Fatalf("%s writer failed: %v", name, err)
+	}
+	return b.String()
+}
+
+func TestLookup(t *testing.T) {
+	if _, err := Lookup("xml"); err == nil {
+		t.Errorf("Lookup(xml) succeeded, want an error")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var doc document
+	if err := json.Unmarshal([]byte(write(t, "json")), &doc); err != nil {
+		t.Fatalf("json output does not parse: %v", err)
+	}
+	if doc.Table != "sysvar57" || len(doc.Variables) != 2 {
+		t.Fatalf("json output = %+v, want 2 variables of sysvar57", doc)
+	}
+	if v := doc.Variables[0]; v.DefaultValue != `+ -><()~*:""&|` || v.Dynamic == nil || !*v.Dynamic {
+		t.Errorf("json variable = %+v, want the ft_boolean_syntax default and dynamic", v)
+	}
+	if v := doc.Variables[1]; v.SystemVar || v.Scope != "" || v.Dynamic != nil {
+		t.Errorf("json variable = %+v, want blank cells to be false, empty or null", v)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	want := testTable()
+	want.SetVersion("5.7")
+	want.SetProvenance(table.Provenance{Source: "server-system-variables.html", SHA256: "abc", ManualVersion: "5.7", Parsed: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), ToolVersion: "v1.0.0"})
+	want.AddConflicts(2)
+	var b bytes.Buffer
+	if err := writeJSON(&b, want); err != nil {
+		t.Fatalf("writeJSON() failed: %v", err)
+	}
+	got, err := ReadJSON(&b)
+	if err != nil {
+		t.Fatalf("ReadJSON() failed: %v", err)
+	}
+	if got.Name() != "sysvar57" || got.Version() != "5.7" || !reflect.DeepEqual(got.Records(), want.Records()) {
+		t.Errorf("ReadJSON() = %s %s %+v, want %+v", got.Name(), got.Version(), got.Records(), want.Records())
+	}
+	if p, _ := got.Provenance(); !reflect.DeepEqual(p, mustProvenance(t, want)) {
+		t.Errorf("ReadJSON() provenance = %+v, want %+v", p, mustProvenance(t, want))
+	}
+	if _, err := ReadJSON(strings.NewReader("[")); err == nil {
+		t.Error("ReadJSON() accepted invalid json")
+	}
+}
+
+// the provenance of a table which must have one
+func mustProvenance(t *testing.T, tbl *table.Table) table.Provenance {
+	p, found := tbl.Provenance()
+	if !found {
+		t.Fatalf("table %s has no provenance", tbl.Name())
+	}
+	return p
+}
+
+func TestNDJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(write(t, "ndjson")), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ndjson output has %d lines, want 2", len(lines))
+	}
+	var rec table.Record
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil || rec.Name != "big-tables" {
+		t.Errorf("ndjson line = %s, %v, want big-tables", lines[1], err)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	got := write(t, "csv")
+	want := "name,cmd_line,option_file,system_var,scope,dynamic,command_line_format,default_value,data_type,min_value,max_value,valid_values,description,source\n" +
+		`ft_boolean_syntax,true,true,true,Global,true,,"+ -><()~*:""""&|",string,,,,The list of operators supported by boolean full-text searches.,refman-5.7/server-system-variables.html` + "\n" +
+		`big-tables,true,true,false,,,,,,,,"ON,OFF",,` + "\n"
+	if got != want {
+		t.Errorf("csv output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	got := write(t, "yaml")
+	for _, want := range []string{
+		"table: \"sysvar57\"\nvariables:\n  - name: \"ft_boolean_syntax\"\n    cmd_line: true\n",
+		`    default_value: "+ -><()~*:\"\"&|"` + "\n",
+		"    dynamic: null\n",
+		`    valid_values: ["ON", "OFF"]` + "\n",
+		`    source: "refman-5.7/server-system-variables.html"` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("yaml output =\n%s\nmissing %q", got, want)
+		}
+	}
+}
+
+// the keys of the YAML document, of its provenance and of its first variable
+func yamlKeys(doc string) (top, provenance, variable []string) {
+	key := func(s string) string { return strings.SplitN(s, ":", 2)[0] }
+	section := ""
+	for _, line := range strings.Split(doc, "\n") {
+		switch {
+		case line == "":
+		case line[0] != ' ':
+			section = key(line)
+			top = append(top, section)
+		case section == "provenance":
+			provenance = append(provenance, key(strings.TrimSpace(line)))
+		case strings.HasPrefix(line, "  - ") && variable != nil:
+			section = "" // only the first variable
+		case strings.HasPrefix(line, "  - "), strings.HasPrefix(line, "    ") && section == "variables":
+			variable = append(variable, key(line[4:]))
+		}
+	}
+	return top, provenance, variable
+}
+
+// the keys of the JSON object in sorted order
+func jsonKeys(t *testing.T, data []byte) []string {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatalf("json output does not parse: %v", err)
+	}
+	var keys []string
+	for k := range object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestYAMLMatchesJSON(t *testing.T) {
+	tbl := testTable()
+	tbl.SetVersion("5.7")
+	tbl.SetProvenance(table.Provenance{Source: "server-system-variables.html"})
+
+	var j, y bytes.Buffer
+	if err := writeJSON(&j, tbl); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeYAML(&y, tbl); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Provenance json.RawMessage
+		Variables  []json.RawMessage
+	}
+	if err := json.Unmarshal(j.Bytes(), &doc); err != nil || doc.Provenance == nil || len(doc.Variables) == 0 {
+		t.Fatalf("json output = %s, want the provenance and variables", j.String())
+	}
+
+	top, provenance, variable := yamlKeys(y.String())
+	for _, keys := range []struct {
+		name       string
+		yaml, json []string
+	}{
+		{"document", top, jsonKeys(t, j.Bytes())},
+		{"provenance", provenance, jsonKeys(t, doc.Provenance)},
+		{"variable", variable, jsonKeys(t, doc.Variables[0])},
+	} {
+		sort.Strings(keys.yaml)
+		if !reflect.DeepEqual(keys.yaml, keys.json) {
+			t.Errorf("yaml %s keys = %q, want the json keys %q", keys.name, keys.yaml, keys.json)
+		}
+	}
+}
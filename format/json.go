@@ -0,0 +1,59 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// document is the top level JSON object
+type document struct {
+	Table      string            `json:"table"`
+	Version    string            `json:"version,omitempty"`
+	Provenance *table.Provenance `json:"provenance,omitempty"`
+	Variables  []table.Record    `json:"variables"`
+}
+
+// a single JSON document holding all the variables
+func writeJSON(w io.Writer, t *table.Table) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	doc := document{Table: t.Name(), Version: t.Version(), Variables: t.Records()}
+	if p, found := t.Provenance(); found {
+		doc.Provenance = &p
+	}
+	return enc.Encode(doc)
+}
+
+// ReadJSON reads the table written in the json format
+func ReadJSON(r io.Reader) (*table.Table, error) {
+	var doc document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("reading the json document: %v", err)
+	}
+	t := table.NewTable(doc.Table)
+	t.SetVersion(doc.Version)
+	for _, rec := range doc.Variables {
+		t.AppendRow(rec.Row())
+	}
+	if doc.Provenance != nil {
+		t.SetProvenance(*doc.Provenance)
+		t.AddConflicts(doc.Provenance.Conflicts)
+	}
+	return t, nil
+}
+
+// one JSON object per line, one per variable
+func writeNDJSON(w io.Writer, t *table.Table) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, rec := range t.Records() {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// yamlString returns s as a double quoted YAML scalar. A JSON string is
+// also a valid YAML one which avoids YAML's many special cases.
+func yamlString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// yamlValue returns a field value as a YAML scalar or flow sequence
+func yamlValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprint(v)
+	case string:
+		return yamlString(v)
+	case []string:
+		s := "["
+		for i := range v {
+			if i > 0 {
+				s += ", "
+			}
+			s += yamlString(v[i])
+		}
+		return s + "]"
+	}
+	return yamlString(fmt.Sprint(v))
+}
+
+// a YAML document with the same structure as the JSON one
+func writeYAML(w io.Writer, t *table.Table) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "table:", yamlString(t.Name()))
+	if t.Version() != "" {
+		fmt.Fprintln(bw, "version:", yamlString(t.Version()))
+	}
+	if p, found := t.Provenance(); found {
+		fmt.Fprintln(bw, "provenance:")
+		fmt.Fprintln(bw, "  source:", yamlString(p.Source))
+		fmt.Fprintln(bw, "  sha256:", yamlString(p.SHA256))
+		fmt.Fprintln(bw, "  manual_version:", yamlString(p.ManualVersion))
+		fmt.Fprintln(bw, "  parsed:", yamlString(p.Parsed.UTC().Format(time.RFC3339)))
+		fmt.Fprintln(bw, "  tool_version:", yamlString(p.ToolVersion))
+		fmt.Fprintln(bw, "  rows:", p.Rows)
+		fmt.Fprintln(bw, "  conflicts:", p.Conflicts)
+	}
+	records := t.Records()
+	if len(records) == 0 {
+		fmt.Fprintln(bw, "variables: []")
+	} else {
+		fmt.Fprintln(bw, "variables:")
+	}
+	for _, rec := range records {
+		for i, v := range fieldValues(rec) {
+			prefix := "    "
+			if i == 0 {
+				prefix = "  - "
+			}
+			fmt.Fprintf(bw, "%s%s: %s\n", prefix, table.RecordFields[i], yamlValue(v))
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sjmudd/mysql-variables-parser/gogen"
+	"github.com/sjmudd/mysql-variables-parser/jsonschema"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// generate source code or a schema from the variables of the given pages
+func generateCommand(args []string) int {
+	generators := []command{
+		{"go", "Generate a Go package holding the variables of each version", generateGo},
+		{"json-schema", "Generate the JSON Schema of my.cnf or mysqld-auto.cnf for a version", generateJSONSchema},
+	}
+	if len(args) > 0 {
+		for _, g := range generators {
+			if g.name == args[0] {
+				return g.run(args[1:])
+			}
+		}
+	}
+
+	w := os.Stderr
+	rc := exitUsage
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		w, rc = os.Stdout, exitOK
+	} else if len(args) > 0 {
+		fmt.Fprintf(w, "unknown generator '%s'\n", args[0])
+	}
+	fmt.Fprintf(w, "Usage: %s generate <generator> [flags] <file_to_parse> ...\n\nGenerators:\n", program())
+	for _, g := range generators {
+		fmt.Fprintf(w, "  %-12s %s\n", g.name, g.summary)
+	}
+	return rc
+}
+
+// the pages given with --input followed by any arguments
+func inputFiles(inputs stringList, args []string) []string {
+	return append(append([]string(nil), inputs...), args...)
+}
+
+// generate a Go package holding the variables of the files, one per version
+func generateGo(args []string) int {
+	flags := newFlagSet("generate go", "[<file_to_parse> ...]", "Generate a Go package holding the variables of each version documented by the pages.")
+	var inputs stringList
+	flags.Var(&inputs, "input", "A page to parse, may be repeated")
+	pkg := flags.String("package", "sysvars", "Name of the generated package")
+	outputFile := flags.String("output", "", "File to write the source to instead of stdout")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	files := inputFiles(inputs, flags.Args())
+	switch {
+	case len(files) == 0:
+		return usageError(flags, "at least one page is needed")
+	case *page.version != "" && len(files) > 1:
+		return usageError(flags, "--version can only be used with a single page")
+	}
+	tables, err := page.parsePages(files)
+	if err != nil {
+		return failure("Failed to read the pages: %v", err)
+	}
+	if err := writeTo(*outputFile, func(w io.Writer) error { return gogen.Write(w, *pkg, tables) }); err != nil {
+		return failure("Failed to generate the package: %v", err)
+	}
+	return exitOK
+}
+
+// generate the JSON Schema of an option file for one of the versions documented by the files
+func generateJSONSchema(args []string) int {
+	flags := newFlagSet("generate json-schema", "[<file_to_parse> ...]", "Generate the JSON Schema of my.cnf or mysqld-auto.cnf for one of the versions documented by the pages.")
+	var inputs stringList
+	flags.Var(&inputs, "input", "A page to parse, may be repeated")
+	outputFile := flags.String("output", "", "File to write the schema to instead of stdout")
+	targetName := flags.String("target", "my.cnf", "The file to describe: my.cnf or mysqld-auto.cnf")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	target, err := jsonschema.LookupTarget(*targetName)
+	if err != nil {
+		return usageError(flags, "%v", err)
+	}
+	files := inputFiles(inputs, flags.Args())
+	switch {
+	case len(files) == 0:
+		return usageError(flags, "at least one page is needed")
+	case *page.version == "" && len(files) > 1:
+		return usageError(flags, "--version is needed to choose between several pages")
+	}
+	if *page.platform == "" {
+		*page.platform = "unix"
+	}
+
+	tables, err := page.parsePages(files)
+	if err != nil {
+		return failure("Failed to read the pages: %v", err)
+	}
+	var selected *table.Table
+	for _, t := range tables {
+		if len(tables) == 1 || t.Version() == *page.version {
+			selected = t
+		}
+	}
+	if selected == nil {
+		return failure("None of the pages documents MySQL %s", *page.version)
+	}
+	if err := writeTo(*outputFile, func(w io.Writer) error { return jsonschema.Write(w, selected, target) }); err != nil {
+		return failure("Failed to generate the schema: %v", err)
+	}
+	return exitOK
+}
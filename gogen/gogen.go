@@ -0,0 +1,176 @@
+// Package gogen generates a Go package holding the system variables so that
+// programs can check settings without parsing the documentation at run time.
+package gogen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// the code which doesn't depend on the variables
+const header = `// Code generated by mysql-variables-parser generate go. DO NOT EDIT.
+
+// Package %[1]s holds the MySQL system variables of the versions %[2]s
+// taken from the MySQL Reference Manual.
+package %[1]s
+
+import "strings"
+
+// Variable describes a system variable in one MySQL version
+type Variable struct {
+	Name              string
+	Version           string
+	CmdLine           bool
+	OptionFile        bool
+	SystemVar         bool
+	Scope             string
+	Dynamic           bool
+	DynamicKnown      bool // false if the documentation doesn't say if it's dynamic
+	CommandLineFormat string
+	DefaultValue      string
+	DataType          string
+	MinValue          string
+	MaxValue          string
+	ValidValues       []string
+}
+
+// CanonicalName returns the name of a variable as used by SET and SHOW
+// VARIABLES: lower case with underscores instead of dashes.
+func CanonicalName(name string) string {
+	return strings.Replace(strings.ToLower(name), "-", "_", -1)
+}
+
+// Lookup returns the variable in the given version
+func Lookup(version, name string) (Variable, bool) {
+	if i, found := index[version][CanonicalName(name)]; found {
+		return Variables[i], true
+	}
+	return Variable{}, false
+}
+
+// LookupAll returns the variable in each version which documents it, oldest first
+func LookupAll(name string) []Variable {
+	var variables []Variable
+	for _, version := range Versions {
+		if v, found := Lookup(version, name); found {
+			variables = append(variables, v)
+		}
+	}
+	return variables
+}
+
+// index maps the version and canonical name to the position in Variables
+var index = func() map[string]map[string]int {
+	index := make(map[string]map[string]int)
+	for i, v := range Variables {
+		if index[v.Version] == nil {
+			index[v.Version] = make(map[string]int)
+		}
+		name := CanonicalName(v.Name)
+		if _, found := index[v.Version][name]; !found {
+			index[v.Version][name] = i
+		}
+	}
+	return index
+}()
+`
+
+// Write writes the Go source of the named package holding the variables of
+// the tables, one table per version. The output only depends on the tables
+// so it can be regenerated with go generate without spurious changes.
+func Write(w io.Writer, pkg string, tables []*table.Table) error {
+	if len(tables) == 0 {
+		return errors.New("no tables to generate the package from")
+	}
+	tables = append([]*table.Table(nil), tables...)
+	for _, t := range tables {
+		if t.Version() == "" {
+			return fmt.Errorf("the MySQL version of table %s is not known", t.Name())
+		}
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		return table.VersionOrder(tables[i].Version()) < table.VersionOrder(tables[j].Version())
+	})
+
+	versions := make([]string, 0, len(tables))
+	for _, t := range tables {
+		versions = append(versions, t.Version())
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, header, pkg, strings.Join(versions, ", "))
+	fmt.Fprintf(&b, "\n// Versions are the MySQL versions described, oldest first\nvar Versions = %s\n", stringSlice(versions))
+	fmt.Fprintf(&b, "\n// Variables holds the variables of every version sorted by version and name\nvar Variables = []Variable{\n")
+	for _, t := range tables {
+		records := t.Records()
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+		for _, rec := range records {
+			writeVariable(&b, t.Version(), rec)
+		}
+	}
+	fmt.Fprintln(&b, "}")
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting the generated source: %v", err)
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+// write the composite literal of the variable, leaving out zero values
+func writeVariable(w io.Writer, version string, rec table.Record) {
+	fmt.Fprintf(w, "{Name: %s, Version: %s", strconv.Quote(rec.Name), strconv.Quote(version))
+	flags := []struct {
+		name  string
+		value bool
+	}{
+		{"CmdLine", rec.CmdLine},
+		{"OptionFile", rec.OptionFile},
+		{"SystemVar", rec.SystemVar},
+		{"Dynamic", rec.Dynamic != nil && *rec.Dynamic},
+		{"DynamicKnown", rec.Dynamic != nil},
+	}
+	for _, f := range flags {
+		if f.value {
+			fmt.Fprintf(w, ", %s: true", f.name)
+		}
+	}
+	strs := []struct {
+		name  string
+		value string
+	}{
+		{"Scope", rec.Scope},
+		{"CommandLineFormat", rec.CommandLineFormat},
+		{"DefaultValue", rec.DefaultValue},
+		{"DataType", rec.DataType},
+		{"MinValue", rec.MinValue},
+		{"MaxValue", rec.MaxValue},
+	}
+	for _, s := range strs {
+		if s.value != "" {
+			fmt.Fprintf(w, ", %s: %s", s.name, strconv.Quote(s.value))
+		}
+	}
+	if len(rec.ValidValues) > 0 {
+		fmt.Fprintf(w, ", ValidValues: %s", stringSlice(rec.ValidValues))
+	}
+	fmt.Fprintln(w, "},")
+}
+
+// the Go literal of a string slice
+func stringSlice(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
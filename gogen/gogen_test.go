@@ -0,0 +1,61 @@
+package gogen
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func testTable(version string, names ...string) *table.Table {
+	t := table.NewTable("sysvar")
+	t.SetVersion(version)
+	for _, name := range names {
+		var r table.Row
+		r.SetSystemVariableName(name)
+		r.SetSystemVar("Yes")
+		r.SetDynamic("Yes")
+		r.SetValidValues([]string{"ON", "OFF"})
+		t.AppendRow(r)
+	}
+	return t
+}
+
+func TestWrite(t *testing.T) {
+	tables := []*table.Table{
+		testTable("8.0", "sql_mode", "autocommit"),
+		testTable("5.7", "autocommit"),
+	}
+	var first, second bytes.Buffer
+	if err := Write(&first, "sysvars", tables); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := Write(&second, "sysvars", []*table.Table{tables[1], tables[0]}); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("Write() output depends on the order of the tables")
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "sysvars.go", first.Bytes(), 0); err != nil {
+		t.Errorf("Write() generated invalid Go: %v", err)
+	}
+
+	got := first.String()
+	for _, want := range []string{
+		"// Code generated by mysql-variables-parser generate go. DO NOT EDIT.\n",
+		"var Versions = []string{\"5.7\", \"8.0\"}\n",
+		"\t{Name: \"autocommit\", Version: \"5.7\", SystemVar: true, Dynamic: true, DynamicKnown: true, ValidValues: []string{\"ON\", \"OFF\"}},\n" +
+			"\t{Name: \"autocommit\", Version: \"8.0\",",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() = %s, missing %q", got, want)
+		}
+	}
+
+	if err := Write(&first, "sysvars", []*table.Table{table.NewTable("no_version")}); err == nil {
+		t.Errorf("Write() of a table without a version succeeded")
+	}
+}
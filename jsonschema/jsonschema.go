@@ -0,0 +1,243 @@
+// Package jsonschema generates JSON Schema documents describing the settings
+// accepted in an option file (my.cnf) or the persisted variables file
+// (mysqld-auto.cnf), so that configuration management can validate them.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/value"
+)
+
+// the version of JSON Schema generated
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Target is the file described by the schema
+type Target int
+
+// The files a schema can be generated for
+const (
+	OptionFile    Target = iota // the [mysqld] section of my.cnf as an object of settings
+	PersistedFile               // mysqld-auto.cnf in the version 1 format written by SET PERSIST
+)
+
+var targetNames = []string{"my.cnf", "mysqld-auto.cnf"}
+
+// String returns the name used to select the target
+func (t Target) String() string {
+	if int(t) < len(targetNames) {
+		return targetNames[t]
+	}
+	return fmt.Sprintf("Target(%d)", int(t))
+}
+
+// LookupTarget returns the named target
+func LookupTarget(name string) (Target, error) {
+	for i, n := range targetNames {
+		if strings.EqualFold(name, n) {
+			return Target(i), nil
+		}
+	}
+	return OptionFile, fmt.Errorf("unknown target '%s', expected one of: %s", name, strings.Join(targetNames, ", "))
+}
+
+// Schema is a JSON Schema document or subschema
+type Schema map[string]interface{}
+
+// the values accepted for a boolean in an option file
+var booleans = []interface{}{"ON", "OFF", "on", "off", "TRUE", "FALSE", "true", "false", "1", "0"}
+
+// an integer with an optional size suffix, as accepted in an option file
+const sizePattern = "^-?[0-9]+[KMGTPEkmgtpe]?$"
+
+// a documented limit which can be given as a JSON number
+var numberRE = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Generate returns the schema of the target for the variables of the table
+func Generate(t *table.Table, target Target) Schema {
+	title := "MySQL " + target.String()
+	if t.Version() != "" {
+		title = "MySQL " + t.Version() + " " + target.String()
+	}
+	schema := Schema{
+		"$schema": draft,
+		"title":   title,
+		"type":    "object",
+	}
+	if target == PersistedFile {
+		dynamic, static := Schema{}, Schema{}
+		for _, rec := range t.Records() {
+			if !rec.SystemVar {
+				continue
+			}
+			row, _ := t.Lookup(rec.Name)
+			property := Schema{
+				"type":                 "object",
+				"description":          description(rec),
+				"properties":           Schema{"Value": persistedValue(row, rec), "Metadata": Schema{"type": "object"}},
+				"required":             []string{"Value"},
+				"additionalProperties": false,
+			}
+			if d, ok := defaultValue(row, rec); ok {
+				property["default"] = Schema{"Value": value.Format(d)}
+			}
+			if rec.Dynamic != nil && *rec.Dynamic {
+				dynamic[table.CanonicalName(rec.Name)] = property
+			} else {
+				static[table.CanonicalName(rec.Name)] = property
+			}
+		}
+		// the static section is inside the dynamic one
+		dynamic[config.PersistedStatic] = sectionSchema(static)
+		schema["properties"] = Schema{
+			"Version":               Schema{"type": "integer", "const": 1},
+			config.PersistedDynamic: sectionSchema(dynamic),
+		}
+		schema["additionalProperties"] = false
+		return schema
+	}
+
+	properties := Schema{}
+	for _, rec := range t.Records() {
+		if !rec.OptionFile {
+			continue
+		}
+		row, _ := t.Lookup(rec.Name)
+		property := optionValue(row, rec)
+		property["description"] = description(rec)
+		if d, ok := defaultValue(row, rec); ok {
+			property["default"] = jsonValue(d)
+		}
+		properties[table.CanonicalName(rec.Name)] = property
+	}
+	schema["properties"] = properties
+	return schema
+}
+
+// the schema of a section of mysqld-auto.cnf
+func sectionSchema(properties Schema) Schema {
+	return Schema{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// Write writes the schema of the target for the variables of the table to w
+func Write(w io.Writer, t *table.Table, target Target) error {
+	b, err := json.MarshalIndent(Generate(t, target), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// the schema of a value in an option file
+func optionValue(row table.Row, rec table.Record) Schema {
+	switch value.TypeOf(row) {
+	case value.Integer:
+		// the range of a value with a suffix can't be checked so it's
+		// only accepted without a documented range
+		integer := withRange(Schema{"type": "integer"}, rec)
+		if len(integer) > 1 {
+			return integer
+		}
+		return Schema{"anyOf": []interface{}{
+			integer,
+			Schema{"type": "string", "pattern": sizePattern},
+		}}
+	case value.Numeric:
+		return withRange(Schema{"type": "number"}, rec)
+	case value.Boolean:
+		return Schema{"anyOf": []interface{}{
+			Schema{"type": "boolean"},
+			Schema{"enum": []interface{}{0, 1}},
+			Schema{"enum": booleans},
+		}}
+	case value.Enumeration:
+		if len(rec.ValidValues) > 0 {
+			return Schema{"type": "string", "enum": rec.ValidValues}
+		}
+	}
+	if rec.DataType == "" {
+		return Schema{}
+	}
+	return Schema{"type": "string"}
+}
+
+// the schema of the Value of a persisted variable, which is always a string
+func persistedValue(row table.Row, rec table.Record) Schema {
+	switch value.TypeOf(row) {
+	case value.Integer:
+		return Schema{"type": "string", "pattern": "^-?[0-9]+$"}
+	case value.Boolean:
+		return Schema{"type": "string", "enum": []string{"ON", "OFF"}}
+	case value.Enumeration:
+		if len(rec.ValidValues) > 0 {
+			return Schema{"type": "string", "enum": rec.ValidValues}
+		}
+	}
+	return Schema{"type": "string"}
+}
+
+// add the documented minimum and maximum if they are numbers
+func withRange(s Schema, rec table.Record) Schema {
+	for keyword, limit := range map[string]string{"minimum": rec.MinValue, "maximum": rec.MaxValue} {
+		if numberRE.MatchString(limit) {
+			s[keyword] = json.Number(limit)
+		}
+	}
+	return s
+}
+
+// the documented default parsed for the type of the variable
+func defaultValue(row table.Row, rec table.Record) (interface{}, bool) {
+	if rec.DefaultValue == "" {
+		return nil, false
+	}
+	d, err := value.Parse(row, rec.DefaultValue)
+	if err != nil {
+		return nil, false
+	}
+	return d, true
+}
+
+// the JSON form of a value returned by value.Parse
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return v
+}
+
+// describe the variable from the documented attributes
+func description(rec table.Record) string {
+	var parts []string
+	if rec.DataType != "" {
+		parts = append(parts, "Type: "+rec.DataType+".")
+	}
+	if rec.Scope != "" {
+		parts = append(parts, "Scope: "+rec.Scope+".")
+	}
+	if rec.Dynamic != nil {
+		if *rec.Dynamic {
+			parts = append(parts, "Dynamic.")
+		} else {
+			parts = append(parts, "Not dynamic: a change needs a restart.")
+		}
+	}
+	if rec.CommandLineFormat != "" {
+		parts = append(parts, "Command line: "+rec.CommandLineFormat+".")
+	}
+	if strings.EqualFold(rec.DataType, value.Set) && len(rec.ValidValues) > 0 {
+		parts = append(parts, "A comma separated list of: "+strings.Join(rec.ValidValues, ", ")+".")
+	}
+	return strings.Join(parts, " ")
+}
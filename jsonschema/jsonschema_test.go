@@ -0,0 +1,125 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func testTable() *table.Table {
+	t := table.NewTable("sysvar")
+	t.SetVersion("5.7")
+
+	var r table.Row
+	r.SetSystemVariableName("max_connections")
+	r.SetOptionFile("Yes")
+	r.SetSystemVar("Yes")
+	r.SetDynamic("Yes")
+	r.SetDataType("Integer")
+	r.SetDefaultValue("151")
+	r.SetMinValue("1")
+	r.SetMaxValue("100000")
+	t.AppendRow(r)
+
+	r = table.Row{}
+	r.SetSystemVariableName("binlog_format")
+	r.SetOptionFile("Yes")
+	r.SetSystemVar("Yes")
+	r.SetDynamic("Yes")
+	r.SetDataType("enumeration")
+	r.SetDefaultValue("ROW")
+	r.SetValidValues([]string{"ROW", "STATEMENT", "MIXED"})
+	t.AppendRow(r)
+
+	r = table.Row{}
+	r.SetSystemVariableName("innodb_log_file_size")
+	r.SetOptionFile("Yes")
+	r.SetSystemVar("Yes")
+	r.SetDynamic("No")
+	r.SetDataType("integer")
+	t.AppendRow(r)
+
+	r = table.Row{}
+	r.SetSystemVariableName("version_comment")
+	r.SetSystemVar("Yes")
+	t.AppendRow(r)
+	return t
+}
+
+// decode the generated schema as a client would see it
+func decode(t *testing.T, tbl *table.Table, target Target) map[string]interface{} {
+	var b bytes.Buffer
+	if err := Write(&b, tbl, target); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &schema); err != nil {
+		t.Fatalf("Write() generated invalid JSON: %v", err)
+	}
+	return schema
+}
+
+func TestOptionFile(t *testing.T) {
+	schema := decode(t, testTable(), OptionFile)
+	properties := schema["properties"].(map[string]interface{})
+	if _, found := properties["version_comment"]; found {
+		t.Errorf("version_comment can't be set in an option file but has a property")
+	}
+
+	connections := properties["max_connections"].(map[string]interface{})
+	want := map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 100000.0}
+	for k, v := range want {
+		if connections[k] != v {
+			t.Errorf("max_connections schema = %v, want %v without a string alternative", connections, want)
+			break
+		}
+	}
+	if _, found := connections["anyOf"]; found {
+		t.Errorf("max_connections schema = %v, accepts a size suffix whose range isn't checked", connections)
+	}
+
+	// without a range a size suffix and a negative value are accepted
+	size := properties["innodb_log_file_size"].(map[string]interface{})["anyOf"].([]interface{})
+	pattern := regexp.MustCompile(size[1].(map[string]interface{})["pattern"].(string))
+	for _, v := range []string{"48M", "-1", "1024"} {
+		if !pattern.MatchString(v) {
+			t.Errorf("innodb_log_file_size pattern %s rejects %s", pattern, v)
+		}
+	}
+	if connections["default"] != 151.0 {
+		t.Errorf("max_connections default = %v, want 151", connections["default"])
+	}
+
+	format := properties["binlog_format"].(map[string]interface{})
+	if !reflect.DeepEqual(format["enum"], []interface{}{"ROW", "STATEMENT", "MIXED"}) || format["default"] != "ROW" {
+		t.Errorf("binlog_format schema = %v, want an enum with default ROW", format)
+	}
+}
+
+func TestPersistedFile(t *testing.T) {
+	schema := decode(t, testTable(), PersistedFile)
+	properties := func(s map[string]interface{}) map[string]interface{} {
+		return s["properties"].(map[string]interface{})
+	}
+	if schema["additionalProperties"] != false {
+		t.Errorf("additionalProperties = %v, want false to reject unknown sections", schema["additionalProperties"])
+	}
+	if _, found := properties(schema)["mysql_server_static_options"]; found {
+		t.Errorf("mysql_server_static_options is at the top level, want it inside mysql_server")
+	}
+	dynamic := properties(schema)["mysql_server"].(map[string]interface{})
+	if _, found := properties(dynamic)["max_connections"]; !found {
+		t.Errorf("dynamic variable max_connections is not in mysql_server")
+	}
+	if _, found := properties(dynamic)["innodb_log_file_size"]; found {
+		t.Errorf("static variable innodb_log_file_size is in mysql_server")
+	}
+	static := properties(dynamic)["mysql_server_static_options"].(map[string]interface{})
+	if _, found := properties(static)["innodb_log_file_size"]; !found {
+		t.Errorf("static variable innodb_log_file_size is not in mysql_server.mysql_server_static_options")
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/validate"
+)
+
+// check the server settings of option files
+func lintCommand(args []string) int {
+	flags := newFlagSet("lint", "<my.cnf> ...", "Check the server settings of option files: unknown variables, variables which\ncan't be set in an option file, invalid values and repeated settings.")
+	input := flags.String("input", defaultInput, "The page documenting the variables, - for stdin")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	if flags.NArg() == 0 {
+		return usageError(flags, "at least one option file is needed")
+	}
+	t, err := page.parsePage(*input)
+	if err != nil {
+		return failure("Failed to read %s: %v", *input, err)
+	}
+
+	rc := exitOK
+	for _, filename := range flags.Args() {
+		problems, err := lint(t, filename)
+		if err != nil {
+			return failure("Failed to read %s: %v", filename, err)
+		}
+		for i := range problems {
+			fmt.Printf("%s:%s\n", filename, problems[i])
+		}
+		if len(problems) > 0 {
+			rc = exitProblems
+		}
+	}
+	return rc
+}
+
+// check the option file returning the problems found
+func lint(t *table.Table, filename string) ([]validate.Problem, error) {
+	fi, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close()
+	options, err := config.ParseOptionFile(fi)
+	if err != nil {
+		return nil, err
+	}
+	return validate.CheckOptions(t, options), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/sjmudd/mysql-variables-parser/mysqldb"
+)
+
+// parse each page and load the variables into a MySQL server
+func loadCommand(args []string) int {
+	flags := newFlagSet("load", "[<file_to_parse> ...]", "Load the variables of every version documented by the pages into a table of a MySQL server.")
+	var inputs stringList
+	flags.Var(&inputs, "input", "A page to parse, may be repeated")
+	dsn := flags.String("dsn", "", "Data source name of the server, e.g. user:pass@tcp(host:3306)/db")
+	batchSize := flags.Int("batch-size", mysqldb.DefaultBatchSize, "Number of rows inserted by each statement")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	files := inputFiles(inputs, flags.Args())
+	switch {
+	case *dsn == "":
+		return usageError(flags, "--dsn is needed")
+	case len(files) == 0:
+		return usageError(flags, "at least one page is needed")
+	case *page.version != "" && len(files) > 1:
+		return usageError(flags, "--version can only be used with a single page")
+	}
+
+	db, err := mysqldb.Open(*dsn)
+	if err != nil {
+		return failure("Failed to connect to %s: %v", *dsn, err)
+	}
+	defer db.Close()
+
+	for _, filename := range files {
+		tables, err := page.parsePages([]string{filename})
+		if err != nil {
+			return failure("Failed to read %s: %v", filename, err)
+		}
+		if err := mysqldb.Load(db, *page.table, tables[0], *batchSize); err != nil {
+			return failure("Failed to load %s: %v", filename, err)
+		}
+	}
+	return exitOK
+}
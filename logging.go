@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/parser"
+)
+
+// the names of the log levels accepted by --log-level
+var logLevels = []struct {
+	name  string
+	level slog.Level
+}{
+	{"trace", parser.LevelTrace},
+	{"debug", slog.LevelDebug},
+	{"info", slog.LevelInfo},
+	{"warn", slog.LevelWarn},
+	{"error", slog.LevelError},
+}
+
+// logLevel is the flag selecting the least severe diagnostics logged
+type logLevel slog.Level
+
+// String returns the name of the level
+func (l *logLevel) String() string {
+	for _, ll := range logLevels {
+		if ll.level == slog.Level(*l) {
+			return ll.name
+		}
+	}
+	return slog.Level(*l).String()
+}
+
+// Set sets the level by name
+func (l *logLevel) Set(value string) error {
+	for _, ll := range logLevels {
+		if ll.name == strings.ToLower(value) {
+			*l = logLevel(ll.level)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level '%s', expected trace, debug, info, warn or error", value)
+}
+
+// logFormat is the flag selecting how the diagnostics are written
+type logFormat string
+
+// String returns the format
+func (f *logFormat) String() string {
+	return string(*f)
+}
+
+// Set sets the format, text or json
+func (f *logFormat) Set(value string) error {
+	switch value {
+	case "text", "json":
+		*f = logFormat(value)
+		return nil
+	}
+	return fmt.Errorf("unknown log format '%s', expected text or json", value)
+}
+
+// name the trace level in the log rather than showing DEBUG-4
+func levelName(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level == parser.LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+	}
+	return a
+}
+
+// newLogger returns the logger of the diagnostics, written to stderr so
+// stdout only has the output asked for
+func newLogger(level slog.Level, format logFormat) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: levelName}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, options))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, options))
+}
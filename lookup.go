@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/format"
+	"github.com/sjmudd/mysql-variables-parser/lookup"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// the pages given with --input, or the default page
+func pages(inputs stringList) []string {
+	if len(inputs) == 0 {
+		return []string{defaultInput}
+	}
+	return inputs
+}
+
+// report an unknown variable with the names which may have been meant
+func unknownVariable(tables []*table.Table, name string) {
+	fmt.Fprintf(os.Stderr, "unknown variable '%s'\n", name)
+	if suggestions := lookup.Suggest(tables, name); len(suggestions) > 0 {
+		fmt.Fprintf(os.Stderr, "did you mean: %s?\n", strings.Join(suggestions, ", "))
+	}
+}
+
+// show the documented attributes of the named variables
+func lookupCommand(args []string) int {
+	flags := newFlagSet("lookup", "<variable> ...", "Show the documented attributes of the named variables with the versions side by side.")
+	var inputs stringList
+	flags.Var(&inputs, "input", "A page documenting the variables, may be repeated to show several versions")
+	formatName := flags.String("format", "text", "Output format: text, or csv, json, ndjson, sql or yaml for each version")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	if flags.NArg() == 0 {
+		return usageError(flags, "at least one variable is needed")
+	}
+	var writer format.Writer
+	if *formatName != "text" {
+		var err error
+		if writer, err = format.Lookup(*formatName); err != nil {
+			return usageError(flags, "%v", err)
+		}
+	}
+	tables, err := page.parsePages(pages(inputs))
+	if err != nil {
+		return failure("Failed to read the pages: %v", err)
+	}
+	tables = lookup.Sort(tables)
+
+	rc := exitOK
+	if writer != nil {
+		found := make([]*table.Table, len(tables))
+		for i, t := range tables {
+			found[i] = table.NewTable(t.Name())
+			found[i].SetVersion(t.Version())
+		}
+		for _, name := range flags.Args() {
+			known := false
+			for i, t := range tables {
+				if row, ok := t.Lookup(name); ok {
+					found[i].AppendRow(row)
+					known = true
+				}
+			}
+			if !known {
+				unknownVariable(tables, name)
+				rc = exitProblems
+			}
+		}
+		for _, t := range found {
+			if err := writeTo("", func(w io.Writer) error { return writer(w, t) }); err != nil {
+				return failure("Failed to write the variables: %v", err)
+			}
+		}
+		return rc
+	}
+
+	for i, name := range flags.Args() {
+		if i > 0 {
+			fmt.Println()
+		}
+		found, err := lookup.Card(os.Stdout, name, tables)
+		if err != nil {
+			return failure("Failed to write the variables: %v", err)
+		}
+		if !found {
+			unknownVariable(tables, name)
+			rc = exitProblems
+		}
+	}
+	return rc
+}
+
+// search the names, descriptions and documented text of the variables
+func searchCommand(args []string) int {
+	flags := newFlagSet("search", "<pattern>", "List the variables whose name, description, command line format, type, default or\npermitted values contain the pattern, ignoring case, with the versions documenting them.")
+	var inputs stringList
+	flags.Var(&inputs, "input", "A page documenting the variables, may be repeated to search several versions")
+	regex := flags.Bool("regex", false, "The pattern is a regular expression")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags, "one pattern is needed")
+	}
+	tables, err := page.parsePages(pages(inputs))
+	if err != nil {
+		return failure("Failed to read the pages: %v", err)
+	}
+	tables = lookup.Sort(tables)
+
+	matches, err := lookup.Search(tables, flags.Arg(0), *regex)
+	if err != nil {
+		return usageError(flags, "invalid pattern: %v", err)
+	}
+	if len(matches) == 0 {
+		unknownVariable(tables, flags.Arg(0))
+		return exitProblems
+	}
+	for _, m := range matches {
+		if len(tables) > 1 {
+			fmt.Printf("%s (%s)\n", m.Name, strings.Join(m.Versions, ", "))
+		} else {
+			fmt.Println(m.Name)
+		}
+	}
+	return exitOK
+}
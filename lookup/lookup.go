@@ -0,0 +1,220 @@
+// Package lookup answers questions about single variables: a card showing
+// the attributes of a variable in each version side by side, searching the
+// variables and suggesting the names meant by a misspelt one.
+package lookup
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// the lines of a card: the label and the attribute of the row shown
+var attributes = []struct {
+	label string
+	value func(r table.Row) string
+}{
+	{"Command-Line Format", table.Row.CommandLineFormat},
+	{"Command line", table.Row.CmdLine},
+	{"Option file", table.Row.OptionFile},
+	{"System variable", table.Row.SystemVar},
+	{"Scope", table.Row.Scope},
+	{"Dynamic", table.Row.Dynamic},
+	{"Type", table.Row.DataType},
+	{"Default", table.Row.DefaultValue},
+	{"Minimum", table.Row.MinValue},
+	{"Maximum", table.Row.MaxValue},
+	{"Permitted values", func(r table.Row) string { return strings.Join(r.ValidValues(), ", ") }},
+	{"Source", table.Row.Source},
+}
+
+// Sort returns the tables ordered by version, oldest first
+func Sort(tables []*table.Table) []*table.Table {
+	tables = append([]*table.Table(nil), tables...)
+	sort.SliceStable(tables, func(i, j int) bool {
+		return table.VersionOrder(tables[i].Version()) < table.VersionOrder(tables[j].Version())
+	})
+	return tables
+}
+
+// the heading of the column of a table
+func heading(t *table.Table) string {
+	if t.Version() != "" {
+		return t.Version()
+	}
+	return t.Name()
+}
+
+// Card writes the attributes of the variable in each table side by side,
+// returning false if no table documents it. The tables should be sorted.
+// A version which doesn't document the variable shows "-".
+func Card(w io.Writer, name string, tables []*table.Table) (bool, error) {
+	rows := make([]table.Row, len(tables))
+	found := false
+	for i, t := range tables {
+		if row, ok := t.Lookup(name); ok {
+			rows[i] = row
+			found = true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	fmt.Fprintln(w, table.CanonicalName(name))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tw, "  ")
+	for _, t := range tables {
+		fmt.Fprintf(tw, "\t%s", heading(t))
+	}
+	fmt.Fprintln(tw)
+	for _, a := range attributes {
+		values := make([]string, len(rows))
+		empty := true
+		for i, row := range rows {
+			switch {
+			case row.Name() == "":
+				values[i] = "-"
+			default:
+				values[i] = a.value(row)
+				empty = empty && values[i] == ""
+			}
+		}
+		if empty {
+			continue
+		}
+		fmt.Fprintf(tw, "  %s", a.label)
+		for _, v := range values {
+			fmt.Fprintf(tw, "\t%s", v)
+		}
+		fmt.Fprintln(tw)
+	}
+	return true, tw.Flush()
+}
+
+// Match is a variable found by Search
+type Match struct {
+	Name     string   `json:"name"`     // the canonical name
+	Versions []string `json:"versions"` // the versions documenting the variable, oldest first
+}
+
+// the text searched besides the name
+func searchText(r table.Row) string {
+	return strings.Join([]string{r.Description(), r.CommandLineFormat(), r.DataType(), r.DefaultValue(), strings.Join(r.ValidValues(), " ")}, "\n")
+}
+
+// Search returns the variables whose name, description or documented text
+// matches the pattern, sorted by name. The pattern is a case insensitive
+// substring, or a regular expression if regex is set. The tables should be
+// sorted.
+func Search(tables []*table.Table, pattern string, regex bool) ([]Match, error) {
+	var re *regexp.Regexp
+	if regex {
+		var err error
+		if re, err = regexp.Compile("(?i)" + pattern); err != nil {
+			return nil, err
+		}
+	}
+	matches := func(s string) bool {
+		if re != nil {
+			return re.MatchString(s)
+		}
+		return strings.Contains(strings.ToLower(s), strings.ToLower(pattern))
+	}
+
+	versions := make(map[string][]string)
+	for _, t := range tables {
+		for _, rec := range t.Records() {
+			name := table.CanonicalName(rec.Name)
+			row, _ := t.Lookup(rec.Name)
+			if !matches(rec.Name) && !matches(name) && !matches(searchText(row)) {
+				continue
+			}
+			if v := versions[name]; len(v) == 0 || v[len(v)-1] != heading(t) {
+				versions[name] = append(v, heading(t))
+			}
+		}
+	}
+
+	found := make([]Match, 0, len(versions))
+	for name, v := range versions {
+		found = append(found, Match{Name: name, Versions: v})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, nil
+}
+
+// Distance returns the Levenshtein edit distance between two strings
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(t)]
+}
+
+// the most suggestions made
+const maxSuggestions = 5
+
+// Suggest returns the names of the variables closest to a name which isn't
+// documented, nearest first. Only names within a third of the length of
+// the name, and at least 2, edits away are suggested.
+func Suggest(tables []*table.Table, name string) []string {
+	name = table.CanonicalName(name)
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	distances := make(map[string]int)
+	for _, t := range tables {
+		for _, rec := range t.Records() {
+			candidate := table.CanonicalName(rec.Name)
+			if _, seen := distances[candidate]; seen {
+				continue
+			}
+			distances[candidate] = Distance(name, candidate)
+		}
+	}
+
+	var names []string
+	for candidate, d := range distances {
+		if d <= limit {
+			names = append(names, candidate)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if distances[names[i]] != distances[names[j]] {
+			return distances[names[i]] < distances[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxSuggestions {
+		names = names[:maxSuggestions]
+	}
+	return names
+}
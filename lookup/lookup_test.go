@@ -0,0 +1,93 @@
+package lookup
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func newTable(version string, rows ...table.Row) *table.Table {
+	t := table.NewTable("sysvar")
+	t.SetVersion(version)
+	for _, r := range rows {
+		t.AppendRow(r)
+	}
+	return t
+}
+
+func newRow(name, dynamic, defaultValue string) table.Row {
+	var r table.Row
+	r.SetSystemVariableName(name)
+	r.SetVarScope("Global")
+	r.SetDynamic(dynamic)
+	r.SetDefaultValue(defaultValue)
+	return r
+}
+
+func testTables() []*table.Table {
+	queryCache := newRow("query_cache_size", "Yes", "1048576")
+	queryCache.SetDescription("The amount of memory allocated for caching query results.")
+	return Sort([]*table.Table{
+		newTable("8.0", newRow("max_connections", "Yes", "151"), newRow("max_execution_time", "Yes", "0")),
+		newTable("5.6", newRow("max_connections", "Yes", "151"), queryCache),
+	})
+}
+
+func TestCard(t *testing.T) {
+	var b bytes.Buffer
+	found, err := Card(&b, "query-cache-size", testTables())
+	if !found || err != nil {
+		t.Fatalf("Card() = %v, %v, want true, nil", found, err)
+	}
+	want := `query_cache_size
+           5.6      8.0
+  Scope    Global   -
+  Dynamic  Yes      -
+  Default  1048576  -
+`
+	if b.String() != want {
+		t.Errorf("Card() wrote\n%s\nwant\n%s", b.String(), want)
+	}
+
+	if found, _ := Card(&b, "no_such_variable", testTables()); found {
+		t.Error("Card() found an unknown variable")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	got, err := Search(testTables(), "MAX_", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Match{
+		{Name: "max_connections", Versions: []string{"5.6", "8.0"}},
+		{Name: "max_execution_time", Versions: []string{"8.0"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() = %+v, want %+v", got, want)
+	}
+
+	if got, _ = Search(testTables(), "^query.*size$", true); len(got) != 1 || got[0].Name != "query_cache_size" {
+		t.Errorf("Search() = %+v, want query_cache_size", got)
+	}
+	if got, _ = Search(testTables(), "memory allocated", false); len(got) != 1 || got[0].Name != "query_cache_size" {
+		t.Errorf("Search() of the descriptions = %+v, want query_cache_size", got)
+	}
+	if _, err := Search(testTables(), "(", true); err == nil {
+		t.Error("Search() accepted an invalid regular expression")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	if d := Distance("kitten", "sitting"); d != 3 {
+		t.Errorf("Distance() = %d, want 3", d)
+	}
+	if got := Suggest(testTables(), "max-conections"); !reflect.DeepEqual(got, []string{"max_connections"}) {
+		t.Errorf("Suggest() = %v, want [max_connections]", got)
+	}
+	if got := Suggest(testTables(), "autocommit"); len(got) != 0 {
+		t.Errorf("Suggest() = %v, want nothing", got)
+	}
+}
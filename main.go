@@ -2,82 +2,104 @@
 package main
 
 import (
-	"flag"
 	"fmt"
+	"io"
 	"os"
-
-	"github.com/sjmudd/mysql-variables-parser/parser"
+	"path/filepath"
 )
 
-var (
-	flag_help    = flag.Bool("help", false, "Provide a usage message")
-	flag_verbose = flag.Bool("verbose", false, "Make output verbose")
+// the exit codes of every command
+const (
+	exitOK       = 0 // success
+	exitProblems = 1 // problems were found, or the versions differ
+	exitUsage    = 2 // the command line is wrong
+	exitFailure  = 3 // the command failed, e.g. a file couldn't be read
 )
 
-// very basic usage message
-func usage(rc int) {
-	fmt.Println(os.Args[0])
-	fmt.Println("Script to parse the server-system-variables.html file and generate table defintions")
-	fmt.Println("for the defined configuration settings")
-	fmt.Println()
-	fmt.Println("Usage: ", os.Args[0], "[--help] [--verbose] [<file_to_parse>] [<table_name>]")
-	os.Exit(rc)
+// command is a subcommand of the program
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
 }
 
-// main loop
-func main() {
-	var (
-		filename  string
-		tablename string
-		parser    parser.Parser
-		defaults  map[string]string
-	)
+// the subcommands in the order shown by the usage message. Set in init as
+// help refers to them.
+var commands []command
 
-	defaults = make(map[string]string)
-	defaults["filename"] = "server-system-variables.html"
-	defaults["tablename"] = "sysvars"
-
-	flag.Parse()
-	if *flag_help {
-		usage(0)
+func init() {
+	commands = []command{
+		{"fetch", "Fetch a page of the manual through an on-disk cache", fetchCommand},
+		{"parse", "Parse a server-system-variables page and write the variables as SQL, CSV, JSON, NDJSON or YAML", parseCommand},
+		{"diff", "Show the variables added, removed or changed between two versions", diffCommand},
+		{"lint", "Check the server settings of option files (my.cnf)", lintCommand},
+		{"lookup", "Show the documented attributes of variables with the versions side by side", lookupCommand},
+		{"search", "Search the names and documented text of the variables", searchCommand},
+		{"validate", "Check SET statements or a mysqld-auto.cnf file", validateCommand},
+		{"plan", "Plan the changes needed to apply an option file to a running server", planCommand},
+		{"serve", "Serve the variables of several versions over HTTP as JSON", serveCommand},
+		{"generate", "Generate a Go package or a JSON Schema from the variables", generateCommand},
+		{"load", "Load the variables of several versions into a MySQL server", loadCommand},
+		{"help", "Show the help of a command", helpCommand},
 	}
-	if *flag_verbose {
-		parser.SetVerbose()
+}
+
+// the name the program was run as
+func program() string {
+	return filepath.Base(os.Args[0])
+}
+
+// usage lists the commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, program())
+	fmt.Fprintln(w, "Script to parse the server-system-variables.html file and generate table defintions")
+	fmt.Fprintln(w, "for the defined configuration settings")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:", program(), "<command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run", program(), "<command> --help for the flags of a command.")
+	fmt.Fprintln(w, "Without a command the arguments are those of parse with an optional")
+	fmt.Fprintln(w, "<file_to_parse> and <table_name>, e.g.", program(), "- sysvar57")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes: 0 success, 1 problems or differences found, 2 usage error, 3 failure")
+}
 
-	args := flag.Args()
-	switch len(args) {
-	case 0:
-		{
-			filename = defaults["filename"]
-			tablename = defaults["tablename"]
-			if *flag_verbose {
-				fmt.Println("no arguments provided")
-				fmt.Println("- using default filename):", filename)
-				fmt.Println("- using default tablename):", tablename)
-			}
-		}
-	case 1:
-		{
-			filename = args[0]
-			tablename = defaults["tablename"]
-			if *flag_verbose {
-				fmt.Println("1 argument (filename):", filename)
-				fmt.Println("- using default tablename):", tablename)
-			}
+// lookup the named command
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
 		}
-	case 2:
-		{
-			filename = args[0]
-			tablename = args[1]
-			if *flag_verbose {
-				fmt.Println("2 arguments provided")
-				fmt.Println("- filename:", filename)
-				fmt.Println("- tablename:", tablename)
-			}
+	}
+	return command{}, false
+}
+
+// help shows the help of the named command, or the list of commands
+func helpCommand(args []string) int {
+	if len(args) == 0 {
+		usage(os.Stdout)
+		return exitOK
+	}
+	c, found := findCommand(args[0])
+	if !found || c.name == "help" {
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n", args[0])
+		usage(os.Stderr)
+		return exitUsage
+	}
+	return c.run([]string{"--help"})
+}
+
+// main loop
+func main() {
+	if len(os.Args) > 1 {
+		if c, found := findCommand(os.Args[1]); found {
+			os.Exit(c.run(os.Args[2:]))
 		}
-	default:
-		usage(1)
 	}
-	parser.Process(filename, tablename)
+	os.Exit(legacyCommand(os.Args[1:]))
 }
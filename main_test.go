@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExitCodes(t *testing.T) {
+	// keep the usage messages out of the test output
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	stderr := os.Stderr
+	os.Stderr = null
+	defer func() { os.Stderr = stderr }()
+
+	missing := filepath.Join(t.TempDir(), "missing.html")
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"unknown flag", []string{"parse", "--no-such-flag"}, exitUsage},
+		{"unexpected argument", []string{"parse", "page.html"}, exitUsage},
+		{"lock tables in a transaction", []string{"parse", "--lock-tables", "--transaction"}, exitUsage},
+		{"diff needs two pages", []string{"diff", "--from", "a.html"}, exitUsage},
+		{"lint needs a file", []string{"lint"}, exitUsage},
+		{"unknown generator", []string{"generate", "rust"}, exitUsage},
+		{"unknown help", []string{"help", "nothing"}, exitUsage},
+		{"missing page", []string{"parse", "--input", missing}, exitFailure},
+		{"legacy missing page", []string{missing, "sysvars"}, exitFailure},
+		{"legacy too many arguments", []string{"a", "b", "c"}, exitUsage},
+	}
+	for _, test := range tests {
+		var rc int
+		if c, found := findCommand(test.args[0]); found {
+			rc = c.run(test.args[1:])
+		} else {
+			rc = legacyCommand(test.args)
+		}
+		if rc != test.want {
+			t.Errorf("%s: exit code %d, want %d", test.name, rc, test.want)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package mysqldb
+
+// the MySQL driver, registered as "mysql"
+import _ "github.com/go-sql-driver/mysql"
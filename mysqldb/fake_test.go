@@ -0,0 +1,109 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// a stand-in for a MySQL server recording what is sent to it
+
+// call is a request received by the fake server
+type call struct {
+	op    string // exec, prepare, stmt exec, stmt close, begin, commit or rollback
+	query string
+	args  []driver.Value
+}
+
+// fakeServer records the calls and fails those chosen by fail
+type fakeServer struct {
+	calls []call
+	fail  func(c call) bool
+}
+
+// open returns a connection pool to the fake server
+func (s *fakeServer) open() *sql.DB {
+	return sql.OpenDB(s)
+}
+
+// record the call returning an error if it should fail
+func (s *fakeServer) record(op, query string, args []driver.Value) error {
+	c := call{op: op, query: query, args: args}
+	s.calls = append(s.calls, c)
+	if s.fail != nil && s.fail(c) {
+		return errors.New("fake failure of " + op)
+	}
+	return nil
+}
+
+// ops returns the operations of the calls in order
+func (s *fakeServer) ops() []string {
+	ops := make([]string, 0, len(s.calls))
+	for _, c := range s.calls {
+		ops = append(ops, c.op)
+	}
+	return ops
+}
+
+func (s *fakeServer) Connect(context.Context) (driver.Conn, error) { return fakeConn{s}, nil }
+func (s *fakeServer) Driver() driver.Driver                        { return fakeDriver{s} }
+
+type fakeDriver struct{ s *fakeServer }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d.s}, nil }
+
+type fakeConn struct{ s *fakeServer }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if err := c.s.record("prepare", query, nil); err != nil {
+		return nil, err
+	}
+	return fakeStmt{c.s, query}, nil
+}
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	if err := c.s.record("begin", "", nil); err != nil {
+		return nil, err
+	}
+	return fakeTx{c.s}, nil
+}
+
+// ExecContext runs statements which aren't prepared, without which
+// database/sql would prepare them
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	var values []driver.Value
+	for _, a := range args {
+		values = append(values, a.Value)
+	}
+	if err := c.s.record("exec", query, values); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+type fakeStmt struct {
+	s     *fakeServer
+	query string
+}
+
+func (st fakeStmt) Close() error  { return st.s.record("stmt close", st.query, nil) }
+func (st fakeStmt) NumInput() int { return -1 }
+
+func (st fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := st.s.record("stmt exec", st.query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (st fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries are not supported")
+}
+
+type fakeTx struct{ s *fakeServer }
+
+func (tx fakeTx) Commit() error   { return tx.s.record("commit", "", nil) }
+func (tx fakeTx) Rollback() error { return tx.s.record("rollback", "", nil) }
@@ -0,0 +1,172 @@
+// Package mysqldb loads the system variables into a MySQL server. All
+// versions are kept in one table keyed on the version and variable name so
+// loading the same version again updates it in place. The provenance of
+// each version is kept in a second table with the suffix _provenance.
+package mysqldb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// DefaultBatchSize is the number of rows inserted by each statement
+const DefaultBatchSize = 100
+
+// the column holding the MySQL version of each row
+const versionColumn = "version"
+
+// Open returns a connection pool for the DSN, e.g. user:pass@tcp(host:3306)/db
+func Open(dsn string) (*sql.DB, error) {
+	return sql.Open("mysql", dsn)
+}
+
+// createTableStatement returns the statement to create the table if it's missing
+func createTableStatement(name string) string {
+	d := table.MySQL
+	definitions := []string{d.QuoteIdentifier(versionColumn) + " varchar(16) NOT NULL"}
+	definitions = append(definitions, table.ColumnDefinitions(d)...)
+	definitions = append(definitions, "PRIMARY KEY ("+d.QuoteIdentifier(versionColumn)+","+d.QuoteIdentifier(table.KeyColumn())+")")
+
+	return "CREATE TABLE IF NOT EXISTS " + d.QuoteIdentifier(name) + " (\n    " +
+		strings.Join(definitions, ",\n    ") + "\n)" + d.TableOptions()
+}
+
+// the name of the table holding the provenance of each version
+func provenanceTableName(name string) string {
+	return name + "_provenance"
+}
+
+// createProvenanceTableStatement returns the statement to create the
+// provenance table if it's missing
+func createProvenanceTableStatement(name string) string {
+	d := table.MySQL
+	definitions := []string{d.QuoteIdentifier(versionColumn) + " varchar(16) NOT NULL"}
+	definitions = append(definitions, table.ProvenanceColumnDefinitions(d)...)
+	definitions = append(definitions, "PRIMARY KEY ("+d.QuoteIdentifier(versionColumn)+")")
+
+	return "CREATE TABLE IF NOT EXISTS " + d.QuoteIdentifier(provenanceTableName(name)) + " (\n    " +
+		strings.Join(definitions, ",\n    ") + "\n)" + d.TableOptions()
+}
+
+// provenanceStatement returns an INSERT of the provenance of a version
+// with placeholders for the values which replaces any already present
+func provenanceStatement(name string) string {
+	d := table.MySQL
+	columns := append([]string{versionColumn}, table.ProvenanceColumns()...)
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, d.QuoteIdentifier(c))
+	}
+	return "INSERT INTO " + d.QuoteIdentifier(provenanceTableName(name)) +
+		" (" + strings.Join(names, ",") + ")" +
+		" VALUES (" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")" +
+		d.Upsert(versionColumn, columns)
+}
+
+// insertStatement returns an INSERT of rows rows with placeholders for the
+// values which updates any rows already present
+func insertStatement(name string, rows int) string {
+	d := table.MySQL
+	columns := append([]string{versionColumn}, table.Columns()...)
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, d.QuoteIdentifier(c))
+	}
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+	values := make([]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		values = append(values, placeholders)
+	}
+
+	return "INSERT INTO " + d.QuoteIdentifier(name) +
+		" (" + strings.Join(names, ",") + ")" +
+		" VALUES " + strings.Join(values, ",") +
+		d.Upsert(table.KeyColumn(), columns)
+}
+
+// Load creates the named table if needed and inserts or updates the
+// variables of t using batches of batchSize rows, in a single transaction.
+// The provenance of t, if known, is recorded too.
+func Load(db *sql.DB, name string, t *table.Table, batchSize int) error {
+	if t.Version() == "" {
+		return errors.New("the MySQL version is not known: it is needed to load the variables")
+	}
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	if _, err := db.Exec(createTableStatement(name)); err != nil {
+		return fmt.Errorf("creating table %s: %v", name, err)
+	}
+	p, found := t.Provenance()
+	if found {
+		if _, err := db.Exec(createProvenanceTableStatement(name)); err != nil {
+			return fmt.Errorf("creating table %s: %v", provenanceTableName(name), err)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := load(tx, name, t, batchSize); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if found {
+		args := append([]interface{}{t.Version()}, p.ColumnValues()...)
+		if _, err := tx.Exec(provenanceStatement(name), args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func load(tx *sql.Tx, name string, t *table.Table, batchSize int) error {
+	var (
+		batch     *sql.Stmt // prepared for a full batch
+		args      []interface{}
+		remaining = t.Records()
+	)
+	defer func() {
+		if batch != nil {
+			batch.Close()
+		}
+	}()
+
+	for len(remaining) > 0 {
+		n := batchSize
+		if n > len(remaining) {
+			n = len(remaining)
+		}
+		args = args[:0]
+		for _, rec := range remaining[:n] {
+			args = append(args, t.Version())
+			args = append(args, rec.ColumnValues()...)
+		}
+		remaining = remaining[n:]
+
+		if n < batchSize {
+			// the last partial batch is only run once so needs no preparation
+			if _, err := tx.Exec(insertStatement(name, n), args...); err != nil {
+				return err
+			}
+			continue
+		}
+		if batch == nil {
+			var err error
+			if batch, err = tx.Prepare(insertStatement(name, batchSize)); err != nil {
+				return err
+			}
+		}
+		if _, err := batch.Exec(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
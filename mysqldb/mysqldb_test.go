@@ -0,0 +1,226 @@
+package mysqldb
+
+import (
+	"database/sql/driver"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func TestInsertStatement(t *testing.T) {
+	got := insertStatement("sysvars", 2)
+	columns := len(table.Columns()) + 1
+	row := "(" + strings.TrimSuffix(strings.Repeat("?,", columns), ",") + ")"
+
+	if !strings.HasPrefix(got, "INSERT INTO `sysvars` (`version`,`system_variable_name`,`cmd_line`,") {
+		t.Errorf("insertStatement() = %s, want the version column first", got)
+	}
+	if !strings.Contains(got, " VALUES "+row+","+row+" ON DUPLICATE KEY UPDATE ") {
+		t.Errorf("insertStatement() = %s, want 2 rows of %d placeholders", got, columns)
+	}
+	if strings.Contains(got, "`system_variable_name`=VALUES") {
+		t.Errorf("insertStatement() = %s, should not update the key", got)
+	}
+}
+
+func TestCreateTableStatement(t *testing.T) {
+	got := createTableStatement("sysvars")
+	if !strings.Contains(got, "PRIMARY KEY (`version`,`system_variable_name`)") {
+		t.Errorf("createTableStatement() = %s, want a key on version and name", got)
+	}
+}
+
+func TestProvenanceStatements(t *testing.T) {
+	create := createProvenanceTableStatement("sysvars")
+	if !strings.Contains(create, "`sysvars_provenance`") || !strings.Contains(create, "PRIMARY KEY (`version`)") {
+		t.Errorf("createProvenanceTableStatement() = %s, want sysvars_provenance keyed on the version", create)
+	}
+	insert := provenanceStatement("sysvars")
+	if !strings.HasPrefix(insert, "INSERT INTO `sysvars_provenance` (`version`,`source`,`sha256`,") {
+		t.Errorf("provenanceStatement() = %s, want the version column first", insert)
+	}
+	if n := strings.Count(insert, "?"); n != len(table.ProvenanceColumns())+1 {
+		t.Errorf("provenanceStatement() has %d placeholders, want %d", n, len(table.ProvenanceColumns())+1)
+	}
+}
+
+// a table of the variables of a version with the rows named
+func loadTable(names ...string) *table.Table {
+	tbl := table.NewTable("sysvars")
+	tbl.SetVersion("5.7")
+	for _, name := range names {
+		var r table.Row
+		r.SetSystemVariableName(name)
+		r.SetSystemVar("Yes")
+		tbl.AppendRow(r)
+	}
+	return tbl
+}
+
+// the arguments received by the driver: the version followed by the values
+func driverArgs(version string, values ...interface{}) []driver.Value {
+	args := []driver.Value{version}
+	for _, v := range values {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			panic(err)
+		}
+		args = append(args, dv)
+	}
+	return args
+}
+
+// the arguments of a batch of records
+func batchArgs(version string, records []table.Record) []driver.Value {
+	var args []driver.Value
+	for _, rec := range records {
+		args = append(args, driverArgs(version, rec.ColumnValues()...)...)
+	}
+	return args
+}
+
+func TestLoad(t *testing.T) {
+	tbl := loadTable("autocommit", "back_log", "basedir", "big_tables", "binlog_format")
+	p := table.Provenance{Source: "server-system-variables.html", SHA256: "abc", Parsed: time.Unix(0, 0).UTC()}
+	tbl.SetProvenance(p)
+	p, _ = tbl.Provenance()
+
+	var server fakeServer
+	db := server.open()
+	defer db.Close()
+	if err := Load(db, "sysvars", tbl, 2); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// the full batches reuse one prepared statement and the partial last
+	// batch is run on its own, all in one transaction
+	want := []call{
+		{op: "exec", query: createTableStatement("sysvars")},
+		{op: "exec", query: createProvenanceTableStatement("sysvars")},
+		{op: "begin"},
+		{op: "prepare", query: insertStatement("sysvars", 2)},
+		{op: "stmt exec", query: insertStatement("sysvars", 2), args: batchArgs("5.7", tbl.Records()[0:2])},
+		{op: "stmt exec", query: insertStatement("sysvars", 2), args: batchArgs("5.7", tbl.Records()[2:4])},
+		{op: "exec", query: insertStatement("sysvars", 1), args: batchArgs("5.7", tbl.Records()[4:])},
+		{op: "stmt close", query: insertStatement("sysvars", 2)},
+		{op: "exec", query: provenanceStatement("sysvars"), args: driverArgs("5.7", p.ColumnValues()...)},
+		{op: "commit"},
+	}
+	if len(server.calls) != len(want) {
+		t.Fatalf("Load() sent %q, want %q", server.ops(), (&fakeServer{calls: want}).ops())
+	}
+	for i := range want {
+		if got := server.calls[i]; !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("call %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+// the statements sent by Load are checked as MySQL would parse them
+func TestLoadSyntax(t *testing.T) {
+	tbl := loadTable("autocommit", "back_log", "basedir", "big_tables", "binlog_format")
+	tbl.SetProvenance(table.Provenance{Source: "server-system-variables.html", SHA256: "abc", Parsed: time.Unix(0, 0).UTC()})
+
+	var server fakeServer
+	db := server.open()
+	defer db.Close()
+	if err := Load(db, "sysvars", tbl, 2); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var checker syntax
+	for _, c := range server.calls {
+		if c.op != "exec" && c.op != "prepare" && c.op != "stmt exec" {
+			continue
+		}
+		placeholders, err := checker.check(c.query)
+		if err != nil {
+			t.Errorf("%s %s: %v", c.op, c.query, err)
+			continue
+		}
+		if c.op != "prepare" && placeholders != len(c.args) {
+			t.Errorf("%s %s: %d placeholders for %d arguments", c.op, c.query, placeholders, len(c.args))
+		}
+	}
+}
+
+func TestSyntax(t *testing.T) {
+	var checker syntax
+	if _, err := checker.check(createTableStatement("t")); err != nil {
+		t.Fatalf("check(%s) failed: %v", createTableStatement("t"), err)
+	}
+	for _, query := range []string{
+		"CREATE TABLE IF NOT EXISTS `u` (\n    `a` varchar NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		"CREATE TABLE IF NOT EXISTS `u` (\n    `a` int,\n    PRIMARY KEY (`b`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		"INSERT INTO `u` (`version`) VALUES (?)",
+		"INSERT INTO `t` (`version`,`nothing`) VALUES (?,?)",
+		"INSERT INTO `t` (`version`,`dynamic`) VALUES (?,?),(?)",
+		"INSERT INTO `t` (`version`,`dynamic`) VALUES (?,?) ON DUPLICATE KEY UPDATE `dynamic`=VALUES(`version`)",
+		"INSERT INTO `t` (`version`,`dynamic`) VALUES (?,?) ON DUPLICATE KEY UPDATE `dynamic`=VALUES(`dynamic`),",
+		"INSERT INTO `t` (`version`,`dynamic`) VALUES ('5.7,?)",
+	} {
+		if _, err := checker.check(query); err == nil {
+			t.Errorf("check(%s) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestLoadRollback(t *testing.T) {
+	tbl := loadTable("autocommit", "back_log", "basedir", "big_tables", "binlog_format")
+
+	// fail the second batch
+	batches := 0
+	server := fakeServer{fail: func(c call) bool {
+		if c.op != "stmt exec" {
+			return false
+		}
+		batches++
+		return batches == 2
+	}}
+	db := server.open()
+	defer db.Close()
+	if err := Load(db, "sysvars", tbl, 2); err == nil {
+		t.Fatal("Load succeeded, want the failure of the second batch")
+	}
+	want := []string{"exec", "begin", "prepare", "stmt exec", "stmt exec", "stmt close", "rollback"}
+	if got := server.ops(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() sent %q, want %q", got, want)
+	}
+}
+
+// TestLoadServer runs Load against a real server, given by MYSQL_TEST_DSN,
+// e.g. root@tcp(127.0.0.1:3306)/test
+func TestLoadServer(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not set")
+	}
+	db, err := Open(dsn)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	tbl := loadTable("autocommit", "back_log", "basedir")
+
+	const name = "mysql_variables_parser_test"
+	db.Exec("DROP TABLE IF EXISTS " + name)
+	defer db.Exec("DROP TABLE IF EXISTS " + name)
+
+	// loading twice should not fail or duplicate rows
+	for i := 0; i < 2; i++ {
+		if err := Load(db, name, tbl, 2); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+	}
+	var rows int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + name).Scan(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 3 {
+		t.Errorf("table has %d rows, want 3", rows)
+	}
+}
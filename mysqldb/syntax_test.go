@@ -0,0 +1,351 @@
+package mysqldb
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// a checker of the MySQL statements sent by Load: CREATE TABLE IF NOT
+// EXISTS and INSERT ... ON DUPLICATE KEY UPDATE. It records the tables
+// created so that an INSERT can only use their columns.
+type syntax struct {
+	tables map[string][]string // the columns of each table created
+}
+
+// the kinds of token
+const (
+	identifierToken = iota // quoted with backticks
+	stringToken
+	numberToken
+	wordToken
+	symbolToken // one of ( ) , = ?
+)
+
+type token struct {
+	kind int
+	text string // an identifier or string without its quotes
+}
+
+// split the statement into tokens
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\n' || c == '\t':
+			i++
+		case c == '`' || c == '\'':
+			var text strings.Builder
+			for i++; ; i++ {
+				if i >= len(query) {
+					return nil, fmt.Errorf("unterminated %c", c)
+				}
+				if query[i] == '\\' && c == '\'' && i+1 < len(query) {
+					i++
+				} else if query[i] == c {
+					if i+1 < len(query) && query[i+1] == c {
+						i++
+					} else {
+						break
+					}
+				}
+				text.WriteByte(query[i])
+			}
+			i++
+			kind := stringToken
+			if c == '`' {
+				kind = identifierToken
+			}
+			tokens = append(tokens, token{kind, text.String()})
+		case strings.IndexByte("(),=?", c) >= 0:
+			tokens = append(tokens, token{symbolToken, string(c)})
+			i++
+		case unicode.IsDigit(rune(c)):
+			start := i
+			for i < len(query) && unicode.IsDigit(rune(query[i])) {
+				i++
+			}
+			tokens = append(tokens, token{numberToken, query[start:i]})
+		case unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(query) && (unicode.IsLetter(rune(query[i])) || unicode.IsDigit(rune(query[i])) || query[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{wordToken, strings.ToUpper(query[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+// a statement being parsed
+type statement struct {
+	tokens []token
+	pos    int
+}
+
+func (s *statement) peek() token {
+	if s.pos < len(s.tokens) {
+		return s.tokens[s.pos]
+	}
+	return token{kind: -1, text: "the end"}
+}
+
+func (s *statement) next() token {
+	t := s.peek()
+	s.pos++
+	return t
+}
+
+// does the next token have the text? If so it's consumed.
+func (s *statement) accept(kind int, text string) bool {
+	if t := s.peek(); t.kind == kind && t.text == text {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// consume the words or symbols which must come next
+func (s *statement) expect(texts ...string) error {
+	for _, text := range texts {
+		kind := wordToken
+		if len(text) == 1 && strings.Contains("(),=?", text) {
+			kind = symbolToken
+		}
+		if !s.accept(kind, text) {
+			return fmt.Errorf("found %q where %s was expected", s.peek().text, text)
+		}
+	}
+	return nil
+}
+
+func (s *statement) identifier() (string, error) {
+	t := s.next()
+	if t.kind != identifierToken {
+		return "", fmt.Errorf("found %q where an identifier was expected", t.text)
+	}
+	return t.text, nil
+}
+
+// a parenthesised list of items
+func (s *statement) list(item func() error) error {
+	if err := s.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if !s.accept(symbolToken, ",") {
+			return s.expect(")")
+		}
+	}
+}
+
+// a parenthesised list of identifiers
+func (s *statement) identifiers() ([]string, error) {
+	var names []string
+	err := s.list(func() error {
+		name, err := s.identifier()
+		names = append(names, name)
+		return err
+	})
+	return names, err
+}
+
+// check checks the statement returning the number of placeholders
+func (c *syntax) check(query string) (int, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return 0, err
+	}
+	s := &statement{tokens: tokens}
+	switch {
+	case s.accept(wordToken, "CREATE"):
+		return 0, c.createTable(s)
+	case s.accept(wordToken, "INSERT"):
+		return c.insert(s)
+	}
+	return 0, fmt.Errorf("unexpected statement %q", s.peek().text)
+}
+
+// the column types used
+var columnTypes = map[string]bool{"VARCHAR": true, "TEXT": true, "INT": true, "BOOLEAN": true, "ENUM": true}
+
+func (c *syntax) createTable(s *statement) error {
+	if err := s.expect("TABLE", "IF", "NOT", "EXISTS"); err != nil {
+		return err
+	}
+	name, err := s.identifier()
+	if err != nil {
+		return err
+	}
+	var columns []string
+	defined := make(map[string]bool)
+	err = s.list(func() error {
+		if s.accept(wordToken, "PRIMARY") {
+			if err := s.expect("KEY"); err != nil {
+				return err
+			}
+			key, err := s.identifiers()
+			for _, k := range key {
+				if !defined[k] {
+					return fmt.Errorf("the key column %s is not defined", k)
+				}
+			}
+			return err
+		}
+		column, err := s.identifier()
+		if err != nil {
+			return err
+		}
+		if defined[column] {
+			return fmt.Errorf("the column %s is defined twice", column)
+		}
+		defined[column] = true
+		columns = append(columns, column)
+		return c.columnType(s)
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.expect("ENGINE", "=", "INNODB", "DEFAULT", "CHARSET", "=", "UTF8MB4"); err != nil {
+		return err
+	}
+	if s.pos != len(s.tokens) {
+		return fmt.Errorf("unexpected %q after the table options", s.peek().text)
+	}
+	if c.tables == nil {
+		c.tables = make(map[string][]string)
+	}
+	c.tables[name] = columns
+	return nil
+}
+
+// the type of a column and its attributes
+func (c *syntax) columnType(s *statement) error {
+	t := s.next()
+	if t.kind != wordToken || !columnTypes[t.text] {
+		return fmt.Errorf("unknown column type %q", t.text)
+	}
+	switch t.text {
+	case "VARCHAR":
+		if err := s.expect("("); err != nil {
+			return err
+		}
+		if s.next().kind != numberToken {
+			return fmt.Errorf("the length of a varchar must be a number")
+		}
+		if err := s.expect(")"); err != nil {
+			return err
+		}
+	case "ENUM":
+		if err := s.list(func() error {
+			if s.next().kind != stringToken {
+				return fmt.Errorf("the values of an enum must be strings")
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if s.accept(wordToken, "NOT") {
+		if err := s.expect("NULL"); err != nil {
+			return err
+		}
+	}
+	if s.accept(wordToken, "DEFAULT") {
+		switch d := s.next(); {
+		case d.kind == stringToken, d.kind == numberToken:
+		case d.kind == wordToken && (d.text == "NULL" || d.text == "TRUE" || d.text == "FALSE"):
+		default:
+			return fmt.Errorf("invalid default %q", d.text)
+		}
+	}
+	return nil
+}
+
+func (c *syntax) insert(s *statement) (int, error) {
+	if err := s.expect("INTO"); err != nil {
+		return 0, err
+	}
+	name, err := s.identifier()
+	if err != nil {
+		return 0, err
+	}
+	created, found := c.tables[name]
+	if !found {
+		return 0, fmt.Errorf("the table %s has not been created", name)
+	}
+	exists := make(map[string]bool)
+	for _, column := range created {
+		exists[column] = true
+	}
+	columns, err := s.identifiers()
+	if err != nil {
+		return 0, err
+	}
+	inserted := make(map[string]bool)
+	for _, column := range columns {
+		if !exists[column] || inserted[column] {
+			return 0, fmt.Errorf("the column %s is not in table %s or is given twice", column, name)
+		}
+		inserted[column] = true
+	}
+
+	if err := s.expect("VALUES"); err != nil {
+		return 0, err
+	}
+	placeholders := 0
+	for {
+		values := 0
+		if err := s.list(func() error {
+			values++
+			return s.expect("?")
+		}); err != nil {
+			return 0, err
+		}
+		if values != len(columns) {
+			return 0, fmt.Errorf("a row has %d values for %d columns", values, len(columns))
+		}
+		placeholders += values
+		if !s.accept(symbolToken, ",") {
+			break
+		}
+	}
+
+	if s.accept(wordToken, "ON") {
+		if err := s.expect("DUPLICATE", "KEY", "UPDATE"); err != nil {
+			return 0, err
+		}
+		for {
+			column, err := s.identifier()
+			if err != nil {
+				return 0, err
+			}
+			if err := s.expect("=", "VALUES", "("); err != nil {
+				return 0, err
+			}
+			value, err := s.identifier()
+			if err != nil {
+				return 0, err
+			}
+			if !inserted[column] || value != column {
+				return 0, fmt.Errorf("%s is updated with the value of %s", column, value)
+			}
+			if err := s.expect(")"); err != nil {
+				return 0, err
+			}
+			if !s.accept(symbolToken, ",") {
+				break
+			}
+		}
+	}
+	if s.pos != len(s.tokens) {
+		return 0, fmt.Errorf("unexpected %q at the end of the INSERT", s.peek().text)
+	}
+	return placeholders, nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sjmudd/mysql-variables-parser/format"
+	"github.com/sjmudd/mysql-variables-parser/parser"
+	"github.com/sjmudd/mysql-variables-parser/sqlitedb"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// the flags of parse describing the output
+type outputFlags struct {
+	format  *string
+	output  *string
+	dialect *string
+	schema  *string
+	mode    *string
+	tx      *bool
+	maxsize *int
+	lock    *bool
+	nokeys  *bool
+	compat  *bool
+	nobs    *bool
+	report  *bool
+}
+
+// add the output flags of parse
+func addOutputFlags(flags *flag.FlagSet) outputFlags {
+	return outputFlags{
+		format:  flags.String("format", "sql", "Output format: csv, json, ndjson, sql or yaml"),
+		output:  flags.String("output", "", "Write the output to the given file. A file ending in .db, .sqlite or .sqlite3 is written as a SQLite database"),
+		dialect: flags.String("dialect", "mysql", "SQL dialect to generate: mysql, postgresql or sqlite"),
+		schema:  flags.String("schema", "flat", "SQL table layout: flat, one table per version, or normalised, which holds every version in the same tables"),
+		mode:    flags.String("sql-mode", "create", "How the SQL treats an existing table: create, create-if-not-exists, replace, upsert or insert-ignore"),
+		tx:      flags.Bool("transaction", false, "Wrap the generated SQL in a transaction"),
+		maxsize: flags.Int("max-statement-size", 0, "Group the rows into multi-row INSERTs of at most this many bytes, 0 for one row per INSERT"),
+		lock:    flags.Bool("lock-tables", false, "Lock the table while inserting the rows (mysql only)"),
+		nokeys:  flags.Bool("disable-keys", false, "Disable the table's keys while inserting the rows (mysql only)"),
+		compat:  flags.Bool("compat", false, "Generate the original table layout with every column as a varchar"),
+		nobs:    flags.Bool("no-backslash-escapes", false, "Generate SQL for servers using the NO_BACKSLASH_ESCAPES sql_mode"),
+		report:  flags.Bool("report", false, "Report to stderr the variables of the summary table without a details table, the details tables without a summary row and those missing a type, default or scope. Exits with 1 if any are found"),
+	}
+}
+
+// check the output flags, returning the writer of the format and a
+// function configuring the table for the output. The flags are checked
+// before the page is read so a mistake is reported at once.
+func (o outputFlags) check() (format.Writer, func(t *table.Table), error) {
+	writer, err := format.Lookup(*o.format)
+	if err != nil {
+		return nil, nil, err
+	}
+	dialect, err := table.LookupDialect(*o.dialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	mode, err := table.LookupSQLMode(*o.mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	normalised := *o.schema == "normalised"
+	switch {
+	case *o.schema != "flat" && !normalised:
+		return nil, nil, fmt.Errorf("unknown schema '%s', expected flat or normalised", *o.schema)
+	case *o.compat && dialect != table.MySQL:
+		return nil, nil, fmt.Errorf("--compat can only be used with the mysql dialect")
+	case *o.compat && normalised:
+		return nil, nil, fmt.Errorf("--compat can't be used with the normalised schema")
+	case mode != table.CreateMode && normalised:
+		return nil, nil, fmt.Errorf("--sql-mode can't be used with the normalised schema, which never drops tables and replaces the version's rows")
+	case (*o.lock || *o.nokeys) && dialect != table.MySQL:
+		return nil, nil, fmt.Errorf("--lock-tables and --disable-keys can only be used with the mysql dialect")
+	case *o.lock && *o.tx:
+		return nil, nil, fmt.Errorf("--lock-tables can't be used with --transaction as LOCK TABLES commits the transaction")
+	case (*o.maxsize != 0 || *o.lock || *o.nokeys) && normalised:
+		return nil, nil, fmt.Errorf("--max-statement-size, --lock-tables and --disable-keys can't be used with the normalised schema")
+	}
+
+	configure := func(t *table.Table) {
+		t.SetCompatible(*o.compat)
+		t.SetDialect(dialect)
+		t.SetNoBackslashEscapes(*o.nobs)
+		t.SetNormalised(normalised)
+		t.SetSQLMode(mode)
+		t.SetTransaction(*o.tx)
+		t.SetMaxStatementSize(*o.maxsize)
+		t.SetLockTables(*o.lock)
+		t.SetDisableKeys(*o.nokeys)
+	}
+	return writer, configure, nil
+}
+
+// is the output file a SQLite database?
+func isDatabase(filename string) bool {
+	switch filepath.Ext(filename) {
+	case ".db", ".sqlite", ".sqlite3":
+		return true
+	}
+	return false
+}
+
+// write the table to the output file, or stdout if there's no file
+func output(t *table.Table, writer format.Writer, filename string) error {
+	if isDatabase(filename) {
+		return sqlitedb.Write(filename, t)
+	}
+	return writeTo(filename, func(w io.Writer) error { return writer(w, t) })
+}
+
+// parse the page and write the table of variables
+func parseCommand(args []string) int {
+	flags := newFlagSet("parse", "", "Parse a server-system-variables page and write the variables as SQL, CSV, JSON, NDJSON or YAML.")
+	input := flags.String("input", defaultInput, "The page to parse, - for stdin")
+	page := addPageFlags(flags)
+	out := addOutputFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	}
+	return parse(flags, *input, page, out)
+}
+
+// parse the page and write it as configured by the flags
+func parse(flags *flag.FlagSet, input string, page pageFlags, out outputFlags) int {
+	writer, configure, err := out.check()
+	if err != nil {
+		return usageError(flags, "%v", err)
+	}
+	t, findings, err := page.parsePageReport(input)
+	if err != nil {
+		return failure("Failed to read %s: %v", input, err)
+	}
+	configure(t)
+	if err := output(t, writer, *out.output); err != nil {
+		return failure("Failed to write output: %v", err)
+	}
+	if *out.report {
+		parser.PrintReport(os.Stderr, findings)
+		if len(findings) > 0 {
+			return exitProblems
+		}
+	}
+	return exitOK
+}
+
+// legacyCommand handles the original command line: the flags of parse
+// followed by an optional <file_to_parse> and <table_name>, and the flags
+// which have since become the validate and plan commands
+func legacyCommand(args []string) int {
+	flags := newFlagSet("", "[<file_to_parse>] [<table_name>]", "The original command line, an alias of parse.")
+	flags.Usage = func() {
+		usage(flags.Output())
+		fmt.Fprintf(flags.Output(), "\nUsage: %s [flags] [<file_to_parse>] [<table_name>]\n\nFlags:\n", program())
+		flags.PrintDefaults()
+	}
+	page := addPageFlags(flags)
+	out := addOutputFlags(flags)
+	set := flags.String("validate-set", "", "Check the SET statements in the given SQL file instead of generating SQL. See validate --set")
+	auto := flags.String("validate-persisted", "", "Check the variables of the given mysqld-auto.cnf file instead of generating SQL. See validate --persisted")
+	render := flags.Bool("render-cnf", false, "With --validate-persisted print the variables as my.cnf settings")
+	optionFile := flags.String("plan", "", "Plan the changes needed to apply the given option file (my.cnf) instead of generating SQL. See plan")
+	current := flags.String("current", "", "File containing the SHOW VARIABLES output of the server to plan changes for")
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+
+	input := defaultInput
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		input = flags.Arg(0)
+	case 2:
+		input = flags.Arg(0)
+		*page.table = flags.Arg(1)
+	default:
+		return usageError(flags, "too many arguments: %v", flags.Args())
+	}
+	page.setupLogging().Debug("legacy command line", "filename", input, "table", *page.table)
+
+	switch {
+	case *set != "":
+		return check(input, page, *set, "", false)
+	case *auto != "":
+		return check(input, page, "", *auto, *render)
+	case *optionFile != "":
+		return plan(input, page, *optionFile, *current)
+	}
+	return parse(flags, input, page, out)
+}
@@ -3,16 +3,28 @@ package parser
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"golang.org/x/net/html"
 
+	"github.com/sjmudd/mysql-variables-parser/position"
 	"github.com/sjmudd/mysql-variables-parser/sysvar"
 	"github.com/sjmudd/mysql-variables-parser/table"
 )
 
+// LevelTrace is the level of the log of every token read, below debug
+const LevelTrace = slog.LevelDebug - 4
+
 const (
 	defaultTableName = "server_system_variables"
 	// TokenHistorySize represents the size of the token history we remember
@@ -60,6 +72,10 @@ const (
 
 */
 
+// the manual version is taken from the page title, e.g.
+// <title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.5 Server System Variables</title>
+var versionRE = regexp.MustCompile(`MySQL ([0-9]+\.[0-9]+) Reference Manual`)
+
 // Handler is a function which processes a token
 type Handler func(html.Token) error
 
@@ -77,49 +93,90 @@ type Parser struct {
 	rowNum       int
 	colNum       int
 	sysvarInfo   sysvar.Info
-	verbose      bool
+	validValues  bool   // inside a "Valid Values" row of a details table
+	platform     string // whose values are used where they differ, e.g. windows
+	source       string // recorded in each row, e.g. the member of an archive
+	filename     string // naming the page in positions, the source if empty
+	summary      bool   // a summary table was found
+	status       bool   // the summary table is of status variables
+	tracker      position.Tracker
+	position     position.Position   // of the current token
+	positions    []position.Position // of the tokens in tokenHistory
+	rowPosition  position.Position   // of the summary row being read
+	contents     Contents
+	inDetails    bool // inside a details table
+	inCell       bool // inside a cell of the summary table
+	cell         strings.Builder
+	description  description
+	logger       *slog.Logger
 }
 
 // Store the last TokenHistorySize tokens in tokenHistory so we can look back
 // position 0 is the current token, 1 is the previous one etc..
+// Their positions in the page are kept in positions.
 func (c *Parser) getToken() html.Token {
+	c.position = c.tracker.Advance(c.tokenizer.Raw())
+	c.sysvarInfo.SetPosition(c.position)
 	token := c.tokenizer.Token()
 
 	th := make(TokenHistory, 0, TokenHistorySize)
 	th = append(th, token)
+	positions := make([]position.Position, 0, TokenHistorySize)
+	positions = append(positions, c.position)
 
 	if c.tokenHistory != nil {
 		for i := range c.tokenHistory {
-			if len(c.tokenHistory) >= TokenHistorySize {
+			if len(th) >= TokenHistorySize {
 				break
 			}
 			th = append(th, c.tokenHistory[i])
+			positions = append(positions, c.positions[i])
 		}
 	}
 	c.tokenHistory = th
+	c.positions = positions
 	c.tokenCount++
-	if c.verbose {
-		c.printTokenHistory()
+	if c.log().Enabled(context.Background(), LevelTrace) {
+		c.traceTokenHistory()
 	}
 
 	return token
 }
 
-func (c *Parser) printTokenHistory() {
-	fmt.Println("tokenHistory length:", len(c.tokenHistory))
+// log the current token with the history before it
+func (c *Parser) traceTokenHistory() {
+	history := make([]string, 0, len(c.tokenHistory))
 	for i := range c.tokenHistory {
-		fmt.Println(" ", i, c.tokenHistory[i].Type, c.tokenHistory[i])
+		history = append(history, fmt.Sprint(i, " ", c.positions[i], " ", c.tokenHistory[i].Type, " ", c.tokenHistory[i]))
 	}
-	fmt.Println("tokenHistory: END")
+	c.log().Log(context.Background(), LevelTrace, "token",
+		"count", c.tokenCount,
+		"position", c.position,
+		"type", c.tokenHistory[0].Type.String(),
+		"token", c.tokenHistory[0].String(),
+		"history", history)
+}
+
+// log a change of the handler of the tokens
+func (c *Parser) stateChange(from, to, reason string) {
+	c.log().Debug("state change", "from", from, "to", to, "reason", reason, "position", c.position)
+}
+
+// log a value found in a details table
+func (c *Parser) found(field, value string, p position.Position) {
+	c.log().Debug("details", "variable", c.sysvarInfo.LastSysvar(), "field", field, "value", value, "position", p)
+}
+
+// Table returns the table built by Process
+func (c *Parser) Table() *table.Table {
+	return c.table
 }
 
-// Process parses the file consuming tokens and finally returning the SQL statements to build a table
+// Process parses the file consuming tokens and building the table of system variables
 func (c *Parser) Process(filename string, tablename string) {
 	var err error
 	var fi *os.File
 
-	c.table = table.NewTable(tablename)
-
 	if filename == "-" {
 		fi = os.Stdin
 	} else {
@@ -135,48 +192,82 @@ func (c *Parser) Process(filename string, tablename string) {
 		}
 	}()
 
-	r := bufio.NewReader(fi) // make a read buffer
+	if err := c.ProcessReader(fi, tablename); err != nil {
+		log.Panic("Failed to consume tokens:", err)
+	}
+}
 
-	c.tokenizer = html.NewTokenizer(r)
+// ProcessReader parses the page read from r building the table of system
+// variables. A page which ends without the closing </html> is accepted.
+// The provenance of the table records the checksum of everything read from
+// r, the version found in the page and when it was parsed.
+func (c *Parser) ProcessReader(r io.Reader, tablename string) error {
+	c.table = table.NewTable(tablename)
+	c.tracker = position.NewTracker(c.name())
+	c.sysvarInfo.SetLogger(c.log())
+	h := sha256.New()
+	r = io.TeeReader(r, h)
+	c.tokenizer = html.NewTokenizer(bufio.NewReader(r))
 	c.handler = c.WaitingForTable
 
-	err = nil
+	var err error
 	done := false
 	for !done {
-		_ = c.tokenizer.Next() // token type - do we need to do this in 2 calls and throw away the token type ?
-		token := c.getToken()
-
-		if c.verbose {
-			fmt.Println("Process(): tokenCount:", c.tokenCount, ", handler:", c.handler, ", err:", err)
+		if c.tokenizer.Next() == html.ErrorToken {
+			if c.tokenizer.Err() != io.EOF {
+				return fmt.Errorf("%s: %v", c.tracker.Advance(nil), c.tokenizer.Err())
+			}
+			c.log().Debug("end of input before the final </html>", "position", c.tracker.Advance(nil))
+			c.finish()
+			break
 		}
+		token := c.getToken()
 		err = c.handler(token)
 		if c.handler == nil || err != nil {
 			done = true
 		}
 	}
 
-	if c.verbose {
-		fmt.Println("Process completed after consuming", c.tokenCount, "tokens")
-	}
+	c.log().Debug("parsed", "source", c.name(), "tokens", c.tokenCount, "rows", c.table.Rows())
 	if err != nil {
-		log.Panic("Failed to consume tokens:", err)
+		return err
+	}
+
+	// anything after </html> is part of the page checksummed
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
 	}
+	c.table.SetProvenance(table.Provenance{
+		Source:        c.source,
+		SHA256:        hex.EncodeToString(h.Sum(nil)),
+		ManualVersion: c.table.Version(),
+		Parsed:        time.Now().UTC(),
+	})
+	return nil
 }
 
 // WaitingForTable processes tokens waiting for the main table to start
 func (c *Parser) WaitingForTable(token html.Token) error {
-	if c.verbose {
-		fmt.Println("WaitingForTable(", token, ")")
+	if version, found := returnManualVersion(c.tokenHistory); found {
+		c.log().Debug("manual version", "version", version, "position", c.position)
+		c.table.SetVersion(version)
 	}
 
 	if token.Data == "table" &&
 		len(token.Attr) > 0 &&
 		token.Attr[0].Key == "summary" &&
-		token.Attr[0].Val == "System Variable Summary" {
+		(token.Attr[0].Val == "System Variable Summary" || token.Attr[0].Val == "Status Variable Summary") {
+		c.summary = true
+		c.status = token.Attr[0].Val == "Status Variable Summary"
 		c.handler = c.ProcessingTable
-		if c.verbose {
-			fmt.Println("STATE CHANGE: WaitingForTable() - change handler to: ProcessingTable")
-		}
+		c.stateChange("WaitingForTable", "ProcessingTable", token.Attr[0].Val)
+	}
+
+	// pages such as innodb-parameters.html only have the details tables
+	if _, found := returnSysvarName(token); found {
+		c.handler = c.WaitingForDetails
+		c.stateChange("WaitingForTable", "WaitingForDetails", "no summary table")
+		return c.WaitingForDetails(token)
 	}
 	return nil
 }
@@ -188,23 +279,27 @@ func (c *Parser) ProcessingTable(token html.Token) error {
 		{
 			switch token.Data {
 			case "tr":
+				c.rowPosition = c.position
 				c.NewRow()
 			case "td":
+				c.EndCol() // the previous cell may not be closed
 				c.NewCol()
 			}
 		}
 	case html.EndTagToken:
 		{
 			switch token.Data {
+			case "td":
+				c.EndCol()
 			case "table":
 				{
+					c.EndCol()
 					c.handler = c.WaitingForDetails
-					if c.verbose {
-						fmt.Println("STATE CHANGE: ProcessingTable() - change handler to: WaitingForDetails")
-					}
+					c.stateChange("ProcessingTable", "WaitingForDetails", "end of the summary table")
 					c.ResetRowCounters()
 				}
 			case "tr":
+				c.EndCol()
 				c.SaveRow()
 				//      printBit(token)
 			}
@@ -233,12 +328,19 @@ func (c *Parser) WaitingForDetails(token html.Token) error {
 				{
 					sysvarName, found := returnSysvarName(token)
 					if found {
-						if c.verbose {
-							fmt.Println("-- sysvar name:", sysvarName)
-						}
+						c.description.state = noDescription
+						c.log().Debug("details table", "variable", sysvarName, "position", c.position)
 						c.sysvarInfo.SaveName(sysvarName)
+						c.contents.Details = append(c.contents.Details, DetailsTable{
+							Name:     sysvarName,
+							Position: c.position,
+							Fields:   make(map[string]bool),
+						})
+						c.inDetails = true
 					}
 				}
+			case "p":
+				c.description.start(c.position)
 			default:
 				/* do nothing */
 			}
@@ -249,67 +351,207 @@ func (c *Parser) WaitingForDetails(token html.Token) error {
 			case "html":
 				{
 					c.handler = nil
-					if c.verbose {
-						fmt.Println("STATE CHANGE: found final </html>, so finish processing file")
-					}
-					c.table.MysqlDump()
-					//					// dump from data
-					//					fmt.Println("-- XXXXXXXX --")
-					//					c.table.MysqlDumpFromSysvars(c.sysvarInfo.ColumnTypes(), c.sysvarInfo.CmdLines(), c.sysvarInfo.Scopes(), c.sysvarInfo.Defaults(), c.sysvarInfo.Dynamics())
+					c.stateChange("WaitingForDetails", "finished", "found the final </html>")
+					c.finish()
 					c.ResetRowCounters()
 				}
+			case "table":
+				if c.inDetails {
+					c.description.state = awaitingDescription
+				}
+				c.inDetails = false
+			case "p":
+				if text, found := c.description.end(); found {
+					c.found("description", text, c.description.position)
+					c.sysvarInfo.SetPosition(c.description.position)
+					c.sysvarInfo.SaveDescription(text)
+				}
+			case "li":
+				// the end of the variable's entry in the list
+				c.description.state = noDescription
 			case "tr":
 				{
+					c.validValues = false
 					columnType, found := returnSysvarType(c.tokenHistory)
 					if found {
-						if c.verbose {
-							fmt.Println("--        type:", columnType)
-						}
+						c.found("type", columnType, c.positions[3])
+						c.sysvarInfo.SetPosition(c.positions[3])
 						c.sysvarInfo.SaveType(columnType)
+						c.recognised("type")
 						return nil
 					}
 					cmdLine, found := returnCommandLine(c.tokenHistory)
 					if found {
-						if c.verbose {
-							fmt.Println("-- sysvar type:", cmdLine)
-						}
+						c.found("command line", cmdLine, c.positions[3])
+						c.sysvarInfo.SetPosition(c.positions[3])
 						c.sysvarInfo.SaveCommandLine(cmdLine)
+						c.recognised("command line")
 						return nil
 					}
 					scope, found := returnSysvarScope(c.tokenHistory)
 					if found {
-						if c.verbose {
-							fmt.Println("--       scope:", scope)
-						}
+						c.found("scope", scope, c.positions[2])
+						c.sysvarInfo.SetPosition(c.positions[2])
 						c.sysvarInfo.SaveScope(scope)
+						c.recognised("scope")
 						return nil
 					}
 					defaultVal, found := returnSysvarDefault(c.tokenHistory)
 					if found {
-						if c.verbose {
-							fmt.Println("--     default:", defaultVal)
-						}
+						c.found("default", defaultVal, c.positions[3])
+						c.sysvarInfo.SetPosition(c.positions[3])
 						c.sysvarInfo.SaveDefault(defaultVal)
+						c.recognised("default")
 						return nil
 					}
 					dynamic, found := returnSysvarDynamic(c.tokenHistory)
 					if found {
-						if c.verbose {
-							fmt.Println("--     dynamic:", dynamic)
-						}
+						c.found("dynamic", dynamic, c.positions[2])
+						c.sysvarInfo.SetPosition(c.positions[2])
 						c.sysvarInfo.SaveDynamic(dynamic)
+						c.recognised("dynamic")
+						return nil
+					}
+					label, value, found := returnLabelledCode(c.tokenHistory)
+					if found {
+						c.sysvarInfo.SetPosition(c.positions[3])
+						switch c.platformLabel(label) {
+						case "Minimum Value", "Min Value":
+							c.found("minimum", value, c.positions[3])
+							c.sysvarInfo.SaveMinimum(value)
+							c.recognised("minimum")
+						case "Maximum Value", "Max Value":
+							c.found("maximum", value, c.positions[3])
+							c.sysvarInfo.SaveMaximum(value)
+							c.recognised("maximum")
+						case "Default", "Default Value":
+							c.found("default", value, c.positions[3])
+							c.sysvarInfo.SaveDefault(value)
+							c.recognised("default")
+						}
 						return nil
 					}
 				}
 			default: /* do nothing */
 			}
 		}
+	case html.TextToken:
+		{
+			if c.description.state == inDescription {
+				c.description.text.WriteString(token.Data)
+				return nil
+			}
+			// the valid values are a list of <code> elements so can't be
+			// matched from the token history: remember we are in the row
+			// and collect each value until the </tr>.
+			if token.Data == "Valid Values" {
+				c.validValues = true
+				return nil
+			}
+			if c.validValues && len(c.tokenHistory) > 1 &&
+				c.tokenHistory[1].Type == html.StartTagToken && c.tokenHistory[1].Data == "code" {
+				c.found("valid value", token.Data, c.position)
+				c.sysvarInfo.SaveValidValue(token.Data)
+			}
+		}
 	default: /* do nothing */
 	}
 
 	return nil
 }
 
+// the states of reading a description
+const (
+	noDescription       = iota
+	awaitingDescription // the details table has ended
+	inDescription       // inside the paragraph following the details table
+)
+
+// description collects the first paragraph following a details table,
+// which describes the variable, up to the end of the variable's entry
+type description struct {
+	state    int
+	text     strings.Builder
+	position position.Position // of the paragraph
+}
+
+// start reading the paragraph at p if it may be the description
+func (d *description) start(p position.Position) {
+	if d.state == awaitingDescription {
+		d.state = inDescription
+		d.text.Reset()
+		d.position = p
+	}
+}
+
+// end the paragraph returning its text if it's the description. An empty
+// paragraph is skipped.
+func (d *description) end() (string, bool) {
+	if d.state != inDescription {
+		return "", false
+	}
+	text := normaliseCell(d.text.String())
+	if text == "" {
+		d.state = awaitingDescription
+		return "", false
+	}
+	d.state = noDescription
+	return text, true
+}
+
+// finish adds the details to the rows of the table. Without a summary
+// table the rows are made from the details tables.
+func (c *Parser) finish() {
+	if !c.summary {
+		c.appendDetailRows()
+	}
+	c.table.MergeSysvarInfo(c.sysvarInfo)
+}
+
+// appendDetailRows adds a row for each details table using what they say
+// about the variable. An option given on the command line can also be set
+// in an option file.
+func (c *Parser) appendDetailRows() {
+	yesIfKnown := func(types sysvar.Types, name string) string {
+		if _, found := types[name]; found {
+			return "Yes"
+		}
+		return ""
+	}
+	for _, name := range c.sysvarInfo.Names() {
+		if _, found := c.table.Lookup(name); found {
+			continue
+		}
+		var row table.Row
+		row.SetSystemVariableName(name)
+		row.SetCmdLine(yesIfKnown(c.sysvarInfo.CmdLines(), name))
+		row.SetOptionFile(yesIfKnown(c.sysvarInfo.CmdLines(), name))
+		row.SetSystemVar(yesIfKnown(c.sysvarInfo.Scopes(), name))
+		row.SetVarScope(c.sysvarInfo.Scopes()[name])
+		row.SetDynamic(c.sysvarInfo.Dynamics()[name])
+		row.SetSource(c.source)
+		if p, found := c.sysvarInfo.Position(name, "name"); found {
+			row.SetPosition(p)
+		}
+		c.table.AppendRow(row)
+	}
+}
+
+// This returns the version of the manual from the page title.
+// <title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.5 Server System Variables</title>
+//    1                                    0
+func returnManualVersion(th TokenHistory) (string, bool) {
+	if th != nil &&
+		len(th) >= 2 &&
+		th[0].Type == html.TextToken &&
+		th[1].Type == html.StartTagToken && th[1].Data == "title" {
+		if m := versionRE.FindStringSubmatch(th[0].Data); m != nil {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
 // This returns the sysvar name.
 // <table summary="Options for flush" border="1">
 //                 0123456789012345678
@@ -415,6 +657,60 @@ func returnSysvarType(th TokenHistory) (string, bool) {
 	return "", false
 }
 
+// Recognise a row with a label and a <code> value, used for the permitted values.
+// Both the "scope=row" and plain <td> forms match as the start of the row is not checked.
+// <tr><td scope="row"><span class="bold"><strong>Minimum Value</strong></span></td><td colspan="2"><code class="literal">1</code></td></tr>
+//                                                9      8       7    6         5                    4            3     2     1    0
+func returnLabelledCode(th TokenHistory) (label string, value string, found bool) {
+	if th != nil &&
+		len(th) >= 10 &&
+		th[0].Type == html.EndTagToken && th[0].Data == "tr" &&
+		th[1].Type == html.EndTagToken && th[1].Data == "td" &&
+		th[2].Type == html.EndTagToken && th[2].Data == "code" &&
+		th[3].Type == html.TextToken && // th[3].Data <<-- is what I'm looking for
+		th[4].Type == html.StartTagToken && th[4].Data == "code" &&
+		th[5].Type == html.StartTagToken && th[5].Data == "td" &&
+		th[6].Type == html.EndTagToken && th[6].Data == "td" &&
+		th[7].Type == html.EndTagToken && th[7].Data == "span" &&
+		th[8].Type == html.EndTagToken && th[8].Data == "strong" &&
+		th[9].Type == html.TextToken {
+		return th[9].Data, th[3].Data, true
+	}
+	return "", "", false
+}
+
+// platformLabel returns the label of a details row without a qualifier
+// naming the platform being parsed for, e.g. "Default Value" for
+// "Default Value (Windows)" when parsing for Windows. An empty string is
+// returned if the qualifier is for something else.
+func (c *Parser) platformLabel(label string) string {
+	i := strings.Index(label, " (")
+	if i < 0 || !strings.HasSuffix(label, ")") {
+		return label
+	}
+	if platformMatches(label[i+2:len(label)-1], c.platform) {
+		return label[:i]
+	}
+	return ""
+}
+
+// check if a qualifier such as "Windows", "Other" or "64-bit platforms"
+// applies to the platform. Only 64 bit platforms are considered.
+func platformMatches(qualifier, platform string) bool {
+	q := strings.ToLower(qualifier)
+	switch {
+	case strings.Contains(q, "64-bit"):
+		return true
+	case strings.Contains(q, "32-bit"):
+		return false
+	case strings.Contains(q, "windows"):
+		return platform == "windows"
+	case q == "other" || strings.Contains(q, "unix"):
+		return platform != "windows"
+	}
+	return platform != "" && strings.Contains(q, platform)
+}
+
 // <tr><td scope="row"><span class="bold"><strong>Variable Scope</strong></span></td><td colspan="2">Global</td></tr>
 //  11         10          9                   8         7           6      5      4   3                2     1   0
 func returnSysvarScope(th TokenHistory) (string, bool) {
@@ -490,8 +786,11 @@ func (c *Parser) NewRow() {
 	// fmt.Println("NEW_ROW: Row", c.rowNum)
 }
 
-// NewCol increments the column count
+// NewCol increments the column count and starts collecting the text of
+// the cell
 func (c *Parser) NewCol() {
+	c.inCell = true
+	c.cell.Reset()
 	c.colNum++
 	//	fmt.Println("NEW_COL: Row:", s.rowNum, "Col:", s.colNum)
 }
@@ -506,23 +805,67 @@ func (c Parser) ColNo() int {
 	return c.colNum
 }
 
-// SetText puts the text in the appropriate field
+// SetText adds the text to that of the current cell. Text outside a cell,
+// such as the white space between cells, is ignored.
 func (c *Parser) SetText(token html.Token) {
 	//	printToken(token)
+	if c.inCell {
+		c.cell.WriteString(token.Data)
+	}
+}
+
+// normaliseCell returns the text of a cell with non-breaking spaces
+// and runs of white space replaced by a single space, trimmed
+func normaliseCell(text string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(text, "\u00a0", " ")), " ")
+}
+
+// EndCol puts the text of the cell, which may have come from several
+// elements such as a link followed by a note, in the appropriate field.
+// A variable name has no spaces so a note after it, e.g. "(see note)",
+// is dropped.
+func (c *Parser) EndCol() {
+	if !c.inCell {
+		return
+	}
+	c.inCell = false
+	text := normaliseCell(c.cell.String())
+	if c.colNum == 1 {
+		if i := strings.IndexByte(text, ' '); i >= 0 {
+			text = text[:i]
+		}
+	}
+	c.setCell(text)
+}
+
+// put the text of the cell in the field of its column
+func (c *Parser) setCell(text string) {
+	if c.status {
+		// Variable Name, Variable Type and Variable Scope
+		switch c.colNum {
+		case 1:
+			c.row.SetSystemVariableName(text)
+		case 2:
+			c.row.SetDataType(text)
+		case 3:
+			c.row.SetVarScope(text)
+		}
+		return
+	}
 
 	switch c.colNum {
 	case 1:
-		c.row.SetSystemVariableName(token.Data)
+		c.row.SetSystemVariableName(text)
 	case 2:
-		c.row.SetCmdLine(token.Data)
+		c.row.SetCmdLine(text)
 	case 3:
-		c.row.SetOptionFile(token.Data)
+		c.row.SetOptionFile(text)
 	case 4:
-		c.row.SetSystemVar(token.Data)
+		c.row.SetSystemVar(text)
 	case 5:
-		c.row.SetVarScope(token.Data)
+		c.row.SetVarScope(text)
 	case 6:
-		c.row.SetDynamic(token.Data)
+		c.row.SetDynamic(text)
 	default:
 		// ignore failure for now
 	}
@@ -539,8 +882,17 @@ func (c Parser) PrintRow() {
 // SaveRow saves the row details in the parser.
 func (c *Parser) SaveRow() {
 	//	fmt.Println()
-	if c.colNum == 6 {
+	if c.status && c.colNum == 3 {
+		c.row.SetSystemVar("No")
+		c.row.SetSource(c.source)
+		c.row.SetPosition(c.rowPosition)
+		c.table.AppendRow(c.row)
+		c.row = table.Row{}
+	} else if c.colNum == 6 {
 		// s.PrintRow()
+		c.contents.Summary = append(c.contents.Summary, SummaryRow{Name: c.row.Name(), Position: c.rowPosition})
+		c.row.SetSource(c.source)
+		c.row.SetPosition(c.rowPosition)
 		c.table.AppendRow(c.row)
 		c.row = table.Row{}
 		// fmt.Printf("Saved row to %s, rows: %d\n", s.table.name, s.table.Rows())
@@ -556,9 +908,46 @@ func (c *Parser) ResetRowCounters() {
 	c.rowNum = 0
 }
 
-// SetVerbose makes logging more verbose
-func (c *Parser) SetVerbose() {
-	c.verbose = true
+// SetLogger sets the logger of the diagnostics: state changes are logged
+// at debug, each token at LevelTrace and conflicting values at warn. By
+// default slog.Default() is used.
+func (c *Parser) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// the logger of the diagnostics
+func (c *Parser) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
+}
+
+// SetPlatform selects the platform whose values are used where the
+// documentation gives different values per platform, e.g. windows. By
+// default the values for Unix are used.
+func (c *Parser) SetPlatform(platform string) {
+	c.platform = strings.ToLower(platform)
+}
+
+// the name of the page in positions
+func (c *Parser) name() string {
+	if c.filename != "" {
+		return c.filename
+	}
+	return c.source
+}
+
+// SetFilename sets the name of the page used in the positions reported,
+// by default its source
+func (c *Parser) SetFilename(filename string) {
+	c.filename = filename
+}
+
+// SetSource sets where the page came from, recorded in each row, e.g. the
+// member of an archive
+func (c *Parser) SetSource(source string) {
+	c.source = source
 }
 
 // printToken prints a formatted token
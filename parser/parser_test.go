@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// a page with the summary table followed by the details tables
+const detailsPage = `<html><head><title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.5 Server System Variables</title></head><body>
+<table summary="System Variable Summary"><tbody>
+<tr><td>back_log</td><td>Yes</td><td>Yes</td><td>Yes</td><td>Global</td><td>No</td></tr>
+<tr><td>binlog_format</td><td>Yes</td><td>Yes</td><td>Yes</td><td>Both</td><td>Yes</td></tr>
+</tbody></table>
+{padding}<table summary="Options for back_log" border="1"><tbody>
+<tr><td scope="row"><span class="bold"><strong>Type</strong></span></td><td colspan="2">Integer</td></tr>
+<tr><td scope="row"><span class="bold"><strong>Minimum Value</strong></span></td><td colspan="2"><code class="literal">1</code></td></tr>
+<tr><td scope="row"><span class="bold"><strong>Maximum Value</strong></span></td><td colspan="2"><code class="literal">65535</code></td></tr>
+</tbody></table>
+<table summary="Options for binlog_format" border="1"><tbody>
+<tr><td scope="row"><span class="bold"><strong>Type</strong></span></td><td colspan="2">Enumeration</td></tr>
+<tr><td scope="row"><span class="bold"><strong>Valid Values</strong></span></td><td colspan="2"><p class="valid-value"><code class="literal">ROW</code></p><p class="valid-value"><code class="literal">STATEMENT</code></p><p class="valid-value"><code class="literal">MIXED</code></p></td></tr>
+</tbody></table>
+</body></html>
+`
+
+// the rows are found wherever they fall in the stream of tokens so the
+// details tables are moved along by up to TokenHistorySize tokens
+func TestPermittedValues(t *testing.T) {
+	for padding := 0; padding < TokenHistorySize; padding++ {
+		page := strings.Replace(detailsPage, "{padding}", strings.Repeat("<br>", padding), 1)
+		filename := filepath.Join(t.TempDir(), "server-system-variables.html")
+		if err := os.WriteFile(filename, []byte(page), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var c Parser
+		c.Process(filename, "sysvar")
+
+		t.Run(fmt.Sprintf("padding %d", padding), func(t *testing.T) {
+			row, found := c.Table().Lookup("back_log")
+			if !found {
+				t.Fatal("back_log not found")
+			}
+			if row.MinValue() != "1" || row.MaxValue() != "65535" {
+				t.Errorf("back_log has the range %q to %q, want 1 to 65535", row.MinValue(), row.MaxValue())
+			}
+			row, found = c.Table().Lookup("binlog_format")
+			if !found {
+				t.Fatal("binlog_format not found")
+			}
+			if want := []string{"ROW", "STATEMENT", "MIXED"}; !reflect.DeepEqual(row.ValidValues(), want) {
+				t.Errorf("binlog_format has the valid values %q, want %q", row.ValidValues(), want)
+			}
+		})
+	}
+}
+
+func TestNormaliseCell(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{" ", ""},
+		{" \n\t ", ""},
+		{"Yes", "Yes"},
+		{"\n  Yes\n", "Yes"},
+		{"Global, Session", "Global, Session"},
+		{"sql_mode (see\n  note)", "sql_mode (see note)"},
+	}
+	for _, test := range tests {
+		if got := normaliseCell(test.text); got != test.want {
+			t.Errorf("normaliseCell(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+// parse a page with the summary table made of the rows
+func parseSummary(t *testing.T, summary string, rows ...string) *table.Table {
+	page := "<html><head><title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.5 Server System Variables</title></head><body>\n" +
+		`<table summary="` + summary + `"><thead><tr><th>Name</th><th>Cmd-Line</th></tr></thead>` + "\n<tbody>\n" +
+		strings.Join(rows, "\n") + "\n</tbody></table>\n</body></html>\n"
+	var c Parser
+	if err := c.ProcessReader(strings.NewReader(page), "sysvar"); err != nil {
+		t.Fatalf("ProcessReader() failed: %v", err)
+	}
+	return c.Table()
+}
+
+// rows of the summary table as found in the manual, each cell a
+// challenge for the parser
+func TestSummaryCells(t *testing.T) {
+	tbl := parseSummary(t, "System Variable Summary",
+		// a link to the details followed by a note
+		`<tr><td><a class="link" href="server-system-variables.html#sysvar_sql_mode"><code class="literal">sql_mode</code></a> (see note)</td><td>Yes</td><td>Yes</td><td>Yes</td><td>Both</td><td>Yes</td></tr>`,
+		// reformatted HTML with white space around the values and between the cells
+		"<tr>\n  <td>\n    <a class=\"link\" href=\"#sysvar_autocommit\">autocommit</a>\n  </td>\n  <td><code class=\"literal\">Yes</code>\n  </td>\n"+
+			"  <td>Yes</td>\n  <td>Yes</td>\n  <td>\n    Both\n  </td>\n  <td>Yes</td>\n</tr>",
+		// blank cells holding a non-breaking space
+		`<tr><td>back_log</td><td>&nbsp;</td><td>&nbsp;</td><td>Yes</td><td>Global</td><td>No</td></tr>`,
+		// the value split over several elements
+		`<tr><td><span class="emphasis">binlog</span>_format</td><td>Yes</td><td>Yes</td><td>Yes</td><td><span>Both</span> </td><td><em>Yes</em></td></tr>`,
+		// cells which are never closed
+		`<tr><td>flush<td>Yes<td>Yes<td>Yes<td>Global<td>Yes</tr>`,
+	)
+
+	tests := []struct {
+		name, cmdLine, systemVar, scope, dynamic string
+	}{
+		{"sql_mode", "Yes", "Yes", "Both", "Yes"},
+		{"autocommit", "Yes", "Yes", "Both", "Yes"},
+		{"back_log", "", "Yes", "Global", "No"},
+		{"binlog_format", "Yes", "Yes", "Both", "Yes"},
+		{"flush", "Yes", "Yes", "Global", "Yes"},
+	}
+	if tbl.Rows() != len(tests) {
+		t.Errorf("the table has %d rows, want %d", tbl.Rows(), len(tests))
+	}
+	for _, test := range tests {
+		row, found := tbl.Lookup(test.name)
+		if !found {
+			t.Errorf("%s not found", test.name)
+			continue
+		}
+		if row.Name() != test.name || row.CmdLine() != test.cmdLine || row.SystemVar() != test.systemVar ||
+			row.Scope() != test.scope || row.Dynamic() != test.dynamic {
+			t.Errorf("%s = %q %q %q %q %q, want %q %q %q %q %q", test.name,
+				row.Name(), row.CmdLine(), row.SystemVar(), row.Scope(), row.Dynamic(),
+				test.name, test.cmdLine, test.systemVar, test.scope, test.dynamic)
+		}
+	}
+}
+
+func TestStatusSummaryCells(t *testing.T) {
+	tbl := parseSummary(t, "Status Variable Summary",
+		"<tr><td><a class=\"link\" href=\"#statvar_Aborted_clients\">Aborted_clients</a></td>\n<td>Integer</td>\n<td>Global</td></tr>",
+		`<tr><td>Com_xxx</td><td>&nbsp;Integer&nbsp;</td><td>Both</td></tr>`,
+	)
+	for _, name := range []string{"Aborted_clients", "Com_xxx"} {
+		row, found := tbl.Lookup(name)
+		if !found {
+			t.Errorf("%s not found", name)
+			continue
+		}
+		if row.DataType() != "Integer" || row.SystemVar() != "No" {
+			t.Errorf("%s has type %q and system variable %q, want Integer and No", name, row.DataType(), row.SystemVar())
+		}
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	page := "<html><head><title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.5 Server System Variables</title></head><body>\n" +
+		`<table summary="System Variable Summary"><tbody>` + "\n" +
+		summaryRow("autocommit") + summaryRow("back_log") + summaryRow("basedir") +
+		"</tbody></table>\n<ul>\n" +
+		// the description follows the details table and has markup inside
+		`<li class="listitem"><p><a name="sysvar_autocommit"></a><code class="literal">autocommit</code></p>` + "\n" +
+		`<div class="informaltable"><table summary="Options for autocommit" border="1"><tbody>` + "\n" +
+		codeRow("Type", "Boolean") + "</tbody></table></div><p>\n" +
+		"  The autocommit mode. If set to 1, all changes to a table take effect\n" +
+		`  <span class="emphasis"><em>immediately</em></span>.` + "\n</p><p>A second paragraph.</p></li>\n" +
+		// no description: the next variable's heading isn't taken
+		`<li class="listitem"><p><a name="sysvar_back_log"></a><code class="literal">back_log</code></p>` + "\n" +
+		`<table summary="Options for back_log" border="1"><tbody>` + "\n" +
+		codeRow("Type", "Integer") + "</tbody></table></li>\n" +
+		// an empty paragraph is skipped
+		`<li class="listitem"><p><a name="sysvar_basedir"></a><code class="literal">basedir</code></p>` + "\n" +
+		`<table summary="Options for basedir" border="1"><tbody>` + "\n" +
+		codeRow("Type", "Directory name") + "</tbody></table><p>&nbsp;</p>\n" +
+		"<p>The path to the MySQL installation base directory.</p></li>\n" +
+		"</ul>\n</body></html>\n"
+
+	var c Parser
+	if err := c.ProcessReader(strings.NewReader(page), "sysvar"); err != nil {
+		t.Fatalf("ProcessReader() failed: %v", err)
+	}
+	for name, want := range map[string]string{
+		"autocommit": "The autocommit mode. If set to 1, all changes to a table take effect immediately.",
+		"back_log":   "",
+		"basedir":    "The path to the MySQL installation base directory.",
+	} {
+		row, found := c.Table().Lookup(name)
+		if !found {
+			t.Errorf("%s not found", name)
+			continue
+		}
+		if row.Description() != want {
+			t.Errorf("the description of %s = %q, want %q", name, row.Description(), want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/position"
+	"github.com/sjmudd/mysql-variables-parser/sysvar"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// the fields every details table is expected to give
+var expectedFields = []string{"type", "default", "scope"}
+
+// SummaryRow is a variable listed in the summary table
+type SummaryRow struct {
+	Name     string
+	Position position.Position
+}
+
+// DetailsTable is an "Options for" table and the fields recognised in it
+type DetailsTable struct {
+	Name     string
+	Position position.Position
+	Fields   map[string]bool
+}
+
+// Contents are the summary rows and details tables of a page
+type Contents struct {
+	Summary []SummaryRow
+	Details []DetailsTable
+}
+
+// Contents returns the summary rows and details tables found by the
+// parser. Rows of a status variable summary are not included as status
+// variables have no details tables.
+func (c *Parser) Contents() Contents {
+	return c.contents
+}
+
+// the details table being read, nil between tables
+func (c *Parser) details() *DetailsTable {
+	if !c.inDetails || len(c.contents.Details) == 0 {
+		return nil
+	}
+	return &c.contents.Details[len(c.contents.Details)-1]
+}
+
+// record that the field was recognised in the details table being read
+func (c *Parser) recognised(field string) {
+	if d := c.details(); d != nil {
+		d.Fields[field] = true
+	}
+}
+
+// the kinds of Finding
+const (
+	NoDetails     = "no-details"     // a variable in the summary has no details table
+	NoSummary     = "no-summary"     // a details table has no row in the summary
+	MissingFields = "missing-fields" // some expected fields of a details table were not recognised
+	Conflicting   = "conflict"       // a value was found twice and disagrees
+)
+
+// Finding is a difference between the summary and details tables, or a
+// value found twice which disagrees
+type Finding struct {
+	Kind     string            `json:"kind"`
+	Name     string            `json:"name"`
+	Missing  []string          `json:"missing,omitempty"` // the fields not recognised
+	Conflict string            `json:"conflict,omitempty"`
+	Position position.Position `json:"position"`
+}
+
+// String describes the finding prefixed by its position
+func (f Finding) String() string {
+	switch f.Kind {
+	case NoDetails:
+		return fmt.Sprintf("%s: %s is in the summary table but has no details table", f.Position, f.Name)
+	case NoSummary:
+		return fmt.Sprintf("%s: %s has a details table but is not in the summary table", f.Position, f.Name)
+	case Conflicting:
+		return f.Conflict
+	}
+	return fmt.Sprintf("%s: the details table of %s has no %s", f.Position, f.Name, strings.Join(f.Missing, ", "))
+}
+
+// Report cross-checks the summary and details tables of the pages, e.g.
+// the members of an archive, so a variable may be summarised in one page
+// and detailed in another. Without any summary rows only the missing
+// fields are reported. The findings are in the order of the pages given.
+func Report(pages ...Contents) []Finding {
+	summarised := make(map[string]bool)
+	detailed := make(map[string]bool)
+	for _, page := range pages {
+		for _, row := range page.Summary {
+			summarised[table.CanonicalName(row.Name)] = true
+		}
+		for _, d := range page.Details {
+			detailed[table.CanonicalName(d.Name)] = true
+		}
+	}
+
+	var findings []Finding
+	for _, page := range pages {
+		var found []Finding
+		for _, row := range page.Summary {
+			if !detailed[table.CanonicalName(row.Name)] {
+				found = append(found, Finding{Kind: NoDetails, Name: row.Name, Position: row.Position})
+			}
+		}
+		for _, d := range page.Details {
+			if len(summarised) > 0 && !summarised[table.CanonicalName(d.Name)] {
+				found = append(found, Finding{Kind: NoSummary, Name: d.Name, Position: d.Position})
+			}
+			var missing []string
+			for _, field := range expectedFields {
+				if !d.Fields[field] {
+					missing = append(missing, field)
+				}
+			}
+			if len(missing) > 0 {
+				found = append(found, Finding{Kind: MissingFields, Name: d.Name, Missing: missing, Position: d.Position})
+			}
+		}
+		sort.SliceStable(found, func(i, j int) bool { return found[i].Position.Offset < found[j].Position.Offset })
+		findings = append(findings, found...)
+	}
+	return findings
+}
+
+// ConflictFindings returns the findings of the conflicts of a table
+func ConflictFindings(conflicts []sysvar.Conflict) []Finding {
+	var findings []Finding
+	for _, c := range conflicts {
+		findings = append(findings, Finding{Kind: Conflicting, Name: c.Name, Conflict: c.String(), Position: c.Position})
+	}
+	return findings
+}
+
+// PrintReport writes the findings one per line followed by their counts
+func PrintReport(w io.Writer, findings []Finding) {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		fmt.Fprintln(w, f)
+		counts[f.Kind]++
+	}
+	fmt.Fprintf(w, "%d variables without a details table, %d details tables without a summary row, %d details tables with missing fields, %d conflicts\n",
+		counts[NoDetails], counts[NoSummary], counts[MissingFields], counts[Conflicting])
+}
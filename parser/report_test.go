@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/position"
+)
+
+// a summary row of the system variables page
+func summaryRow(name string) string {
+	return "<tr><td>" + name + "</td><td>Yes</td><td>Yes</td><td>Yes</td><td>Global</td><td>Yes</td></tr>\n"
+}
+
+// a details row giving a value in <code>
+func codeRow(label, value string) string {
+	return `<tr><td scope="row"><span class="bold"><strong>` + label + `</strong></span></td><td colspan="2"><code class="literal">` + value + "</code></td></tr>\n"
+}
+
+// a details row giving a plain value
+func textRow(label, value string) string {
+	return `<tr><td scope="row"><span class="bold"><strong>` + label + `</strong></span></td><td colspan="2">` + value + "</td></tr>\n"
+}
+
+const reportPage = "<html><head><title>MySQL :: MySQL 5.7 Reference Manual :: 5.1.5 Server System Variables</title></head><body>\n" +
+	`<table summary="System Variable Summary"><tbody>` + "\n" +
+	"{summary}</tbody></table>\n" +
+	`<table summary="Options for autocommit" border="1"><tbody>` + "\n" +
+	"{autocommit}</tbody></table>\n" +
+	`<table summary="Options for undocumented" border="1"><tbody>` + "\n" +
+	"{undocumented}</tbody></table>\n" +
+	"</body></html>\n"
+
+func TestReport(t *testing.T) {
+	page := strings.NewReplacer(
+		"{summary}", summaryRow("autocommit")+summaryRow("back_log"),
+		"{autocommit}", textRow("Variable Scope", "Global, Session")+codeRow("Type", "Boolean")+codeRow("Default Value", "ON"),
+		"{undocumented}", codeRow("Type", "Integer"),
+	).Replace(reportPage)
+
+	var c Parser
+	c.SetSource("server-system-variables.html")
+	if err := c.ProcessReader(strings.NewReader(page), "sysvar"); err != nil {
+		t.Fatalf("ProcessReader() failed: %v", err)
+	}
+	at := func(s string) position.Position {
+		offset := strings.Index(page, s)
+		line := strings.Count(page[:offset], "\n") + 1
+		return position.Position{
+			Source: "server-system-variables.html",
+			Offset: offset,
+			Line:   line,
+			Column: offset - strings.LastIndex(page[:offset], "\n"),
+		}
+	}
+
+	want := []Finding{
+		{Kind: NoDetails, Name: "back_log", Position: at("<tr><td>back_log")},
+		{Kind: NoSummary, Name: "undocumented", Position: at(`<table summary="Options for undocumented"`)},
+		{Kind: MissingFields, Name: "undocumented", Missing: []string{"default", "scope"}, Position: at(`<table summary="Options for undocumented"`)},
+	}
+	got := Report(c.Contents())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Report() = %+v, want %+v", got, want)
+	}
+
+	var b bytes.Buffer
+	PrintReport(&b, got)
+	if line := strings.Split(b.String(), "\n")[0]; line != want[0].Position.String()+": back_log is in the summary table but has no details table" {
+		t.Errorf("PrintReport() first line = %q", line)
+	}
+	if !strings.Contains(b.String(), "1 variables without a details table, 1 details tables without a summary row, 1 details tables with missing fields, 0 conflicts") {
+		t.Errorf("PrintReport() = %s, want the counts", b.String())
+	}
+}
+
+func TestReportAcrossPages(t *testing.T) {
+	summary := Contents{Summary: []SummaryRow{{Name: "innodb_buffer_pool_size"}}}
+	details := Contents{Details: []DetailsTable{{
+		Name:   "innodb-buffer-pool-size",
+		Fields: map[string]bool{"type": true, "default": true, "scope": true},
+	}}}
+	if got := Report(summary, details); len(got) != 0 {
+		t.Errorf("Report() = %+v, want the variable detailed in another page to be found", got)
+	}
+	if got := Report(details); len(got) != 0 {
+		t.Errorf("Report() without a summary table = %+v, want no findings", got)
+	}
+}
+
+func TestConflictPositions(t *testing.T) {
+	page := strings.NewReplacer(
+		"{summary}", summaryRow("autocommit"),
+		"{autocommit}", codeRow("Type", "Boolean")+codeRow("Type", "Integer"),
+		"{undocumented}", "",
+	).Replace(reportPage)
+
+	var logged bytes.Buffer
+	var c Parser
+	c.SetFilename("page.html")
+	c.SetLogger(slog.New(slog.NewJSONHandler(&logged, nil)))
+	if err := c.ProcessReader(strings.NewReader(page), "sysvar"); err != nil {
+		t.Fatalf("ProcessReader() failed: %v", err)
+	}
+	var warning struct {
+		Level    string
+		Msg      string
+		Variable string
+		Position struct{ Line int }
+	}
+	if err := json.Unmarshal(logged.Bytes(), &warning); err != nil {
+		t.Fatalf("the log %q is not a single JSON record: %v", logged.String(), err)
+	}
+	if warning.Level != "WARN" || warning.Variable != "autocommit" || warning.Position.Line != 7 {
+		t.Errorf("logged %+v, want a warning about autocommit on line 7", warning)
+	}
+	conflicts := c.Table().Conflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("Conflicts() = %+v, want 1", conflicts)
+	}
+	first := strings.Index(page, "Boolean")
+	second := strings.Index(page, "Integer")
+	if got := conflicts[0]; got.Field != "type" || got.PreviousPosition.Offset != first || got.Position.Offset != second {
+		t.Errorf("Conflicts() = %+v, want the type at offsets %d and %d", got, first, second)
+	}
+	if findings := ConflictFindings(conflicts); len(findings) != 1 || !strings.HasPrefix(findings[0].String(), "page.html:7:") {
+		t.Errorf("ConflictFindings() = %+v, want the conflict on line 7 of page.html", findings)
+	}
+
+	row, _ := c.Table().Lookup("autocommit")
+	if p := row.Position(); p.Line != 3 || p.Column != 1 {
+		t.Errorf("Position() of the row = %s, want line 3 column 1", p)
+	}
+}
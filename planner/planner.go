@@ -0,0 +1,133 @@
+// Package planner works out how to move a running server from its current
+// settings to those of an option file: dynamic variables can be changed
+// with SET GLOBAL, the others need an option file edit and a restart.
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/util"
+	"github.com/sjmudd/mysql-variables-parser/value"
+)
+
+// Change is a variable whose desired value differs from the current one
+type Change struct {
+	Variable string
+	Current  string
+	Desired  string
+	Line     int // line in the option file
+}
+
+// Plan holds the statements and edits needed to apply the option file
+type Plan struct {
+	Statements []string // SET GLOBAL statements
+	Runtime    []Change // the changes made by Statements
+	Restart    []Change // changes which need an option file edit and a restart
+	Unverified []Change // dynamic variables missing from SHOW VARIABLES
+	Warnings   []string
+}
+
+// normalise a value so that the option file and SHOW VARIABLES forms compare equal.
+// A boolean option given without a value is enabled.
+func normalise(row table.Row, s string) string {
+	if s == "" && value.TypeOf(row) == value.Boolean {
+		return "ON"
+	}
+	if v, err := value.Parse(row, s); err == nil {
+		s = value.Format(v)
+	}
+	return strings.ToUpper(s)
+}
+
+// sqlValue returns the value as it should be written in a SET statement
+func sqlValue(row table.Row, s string) string {
+	v, err := value.Parse(row, s)
+	if err == nil {
+		switch v.(type) {
+		case bool, int64, uint64, float64:
+			return value.Format(v)
+		}
+		s = value.Format(v)
+	}
+	return util.QuoteString(s)
+}
+
+// New compares the current variables, as shown by SHOW VARIABLES, with the
+// server options of an option file and returns the plan to apply them.
+func New(t *table.Table, current map[string]string, options []config.Option) Plan {
+	var plan Plan
+
+	for _, o := range options {
+		if !o.IsServer() {
+			continue
+		}
+		name := table.CanonicalName(o.Name)
+		row, found := t.Lookup(name)
+		if !found {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("line %d: unknown variable '%s'", o.Line, o.Name))
+			continue
+		}
+		change := Change{Variable: name, Desired: o.Value, Line: o.Line}
+		currentValue, known := current[name]
+		if known {
+			change.Current = currentValue
+			if normalise(row, currentValue) == normalise(row, o.Value) {
+				continue
+			}
+		}
+		if row.SystemVar() != "Yes" || row.Dynamic() != "Yes" {
+			plan.Restart = append(plan.Restart, change)
+			continue
+		}
+		// the server may not have the variable, e.g. its plugin isn't
+		// loaded, so it can't be known whether a SET would work
+		if !known {
+			plan.Unverified = append(plan.Unverified, change)
+			continue
+		}
+		if row.Scope() == "Session" {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("line %d: '%s' is a session variable and can not be set globally", o.Line, name))
+			continue
+		}
+		plan.Statements = append(plan.Statements, "SET GLOBAL "+name+" = "+sqlValue(row, o.Value)+";")
+		plan.Runtime = append(plan.Runtime, change)
+		if row.Scope() == "Both" {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("line %d: '%s' has session scope: the new value only affects new connections", o.Line, name))
+		}
+	}
+	return plan
+}
+
+// Print writes the plan as a SQL script with the restart changes as comments
+func (p Plan) Print() {
+	fmt.Println("-- Runtime changes")
+	for i := range p.Statements {
+		fmt.Println(p.Statements[i])
+	}
+	if len(p.Warnings) > 0 {
+		fmt.Println("-- Warnings")
+		for i := range p.Warnings {
+			fmt.Println("-- WARNING:", p.Warnings[i])
+		}
+	}
+	if len(p.Unverified) > 0 {
+		fmt.Println("-- Not in SHOW VARIABLES: the current value is unknown")
+		for _, c := range p.Unverified {
+			fmt.Printf("-- %s = %s\n", c.Variable, c.Desired)
+		}
+	}
+	if len(p.Restart) > 0 {
+		fmt.Println("-- Requires restart: edit the option file")
+		fmt.Println("-- [mysqld]")
+		for _, c := range p.Restart {
+			if c.Current == "" {
+				fmt.Printf("-- %s = %s\n", c.Variable, c.Desired)
+			} else {
+				fmt.Printf("-- %s = %s (currently %s)\n", c.Variable, c.Desired, c.Current)
+			}
+		}
+	}
+}
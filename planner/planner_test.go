@@ -0,0 +1,81 @@
+package planner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func newRow(name, scope, dynamic, dataType string) table.Row {
+	var r table.Row
+	r.SetSystemVariableName(name)
+	r.SetSystemVar("Yes")
+	r.SetVarScope(scope)
+	r.SetDynamic(dynamic)
+	r.SetDataType(dataType)
+	return r
+}
+
+func TestNew(t *testing.T) {
+	tbl := table.NewTable("test")
+	tbl.AppendRow(newRow("back_log", "Global", "No", "integer"))
+	tbl.AppendRow(newRow("max_connections", "Global", "Yes", "integer"))
+	tbl.AppendRow(newRow("sort_buffer_size", "Both", "Yes", "integer"))
+	tbl.AppendRow(newRow("slow_query_log", "Global", "Yes", "boolean"))
+	tbl.AppendRow(newRow("tmpdir", "Global", "No", "directory name"))
+	tbl.AppendRow(newRow("rpl_semi_sync_master_enabled", "Global", "Yes", "boolean"))
+	tbl.AppendRow(newRow("innodb_log_file_size", "Global", "No", "integer"))
+
+	current, err := config.ParseVariables(strings.NewReader(`Variable_name	Value
+back_log	80
+max_connections	151
+slow_query_log	ON
+sort_buffer_size	262144
+tmpdir	/tmp
+`))
+	if err != nil {
+		t.Fatalf("ParseVariables failed: %v", err)
+	}
+	options, err := config.ParseOptionFile(strings.NewReader(`
+[client]
+port = 3307
+
+[mysqld]
+back_log = 100
+max_connections = 500   # more than the default
+loose-slow_query_log = 1
+sort_buffer_size = 2M
+tmpdir = "/tmp"
+no_such_variable = 1
+rpl_semi_sync_master_enabled = ON
+innodb_log_file_size = 48M
+`))
+	if err != nil {
+		t.Fatalf("ParseOptionFile failed: %v", err)
+	}
+
+	plan := New(tbl, current, options)
+
+	wantStatements := []string{
+		"SET GLOBAL max_connections = 500;",
+		"SET GLOBAL sort_buffer_size = 2097152;",
+	}
+	if strings.Join(plan.Statements, "\n") != strings.Join(wantStatements, "\n") {
+		t.Errorf("New().Statements = %q, want %q", plan.Statements, wantStatements)
+	}
+	if len(plan.Restart) != 2 || plan.Restart[0].Variable != "back_log" || plan.Restart[0].Current != "80" ||
+		plan.Restart[1].Variable != "innodb_log_file_size" || plan.Restart[1].Current != "" {
+		t.Errorf("New().Restart = %+v, want back_log and innodb_log_file_size", plan.Restart)
+	}
+	// a dynamic variable missing from SHOW VARIABLES doesn't need a restart
+	if len(plan.Unverified) != 1 || plan.Unverified[0].Variable != "rpl_semi_sync_master_enabled" {
+		t.Errorf("New().Unverified = %+v, want only rpl_semi_sync_master_enabled", plan.Unverified)
+	}
+	if len(plan.Warnings) != 2 ||
+		!strings.Contains(plan.Warnings[0], "sort_buffer_size") ||
+		!strings.Contains(plan.Warnings[1], "no_such_variable") {
+		t.Errorf("New().Warnings = %q, want warnings for sort_buffer_size and no_such_variable", plan.Warnings)
+	}
+}
@@ -0,0 +1,59 @@
+// Package position tracks where in a page a value was found so a mis-parse
+// can be traced straight back to the HTML.
+package position
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Position is a place in a page
+type Position struct {
+	Source string `json:"source,omitempty"` // the page, e.g. the member of an archive
+	Offset int    `json:"offset"`           // in bytes from the start of the page
+	Line   int    `json:"line"`             // from 1
+	Column int    `json:"column"`           // in characters from 1
+}
+
+// IsValid reports whether the position is known
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as source:line:column, the source alone if
+// the position isn't known
+func (p Position) String() string {
+	source := p.Source
+	if source == "" {
+		source = "-"
+	}
+	if !p.IsValid() {
+		return source
+	}
+	return fmt.Sprintf("%s:%d:%d", source, p.Line, p.Column)
+}
+
+// Tracker follows the position through the text of a page as it's read
+type Tracker struct {
+	next Position
+}
+
+// NewTracker returns a tracker at the start of the page
+func NewTracker(source string) Tracker {
+	return Tracker{next: Position{Source: source, Line: 1, Column: 1}}
+}
+
+// Advance returns the position of the text, which is the next read from
+// the page, moving past it
+func (t *Tracker) Advance(text []byte) Position {
+	p := t.next
+	t.next.Offset += len(text)
+	if i := bytes.LastIndexByte(text, '\n'); i >= 0 {
+		t.next.Line += bytes.Count(text, []byte{'\n'})
+		t.next.Column = 1 + utf8.RuneCount(text[i+1:])
+	} else {
+		t.next.Column += utf8.RuneCount(text)
+	}
+	return p
+}
@@ -0,0 +1,28 @@
+package position
+
+import "testing"
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker("page.html")
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"<html>", "page.html:1:1"},
+		{"\n<p>", "page.html:1:7"},
+		{"café ", "page.html:2:4"},
+		{"</p>\n\n", "page.html:2:9"},
+		{"<td>", "page.html:4:1"},
+	}
+	for _, test := range tests {
+		if got := tracker.Advance([]byte(test.text)).String(); got != test.want {
+			t.Errorf("Advance(%q) = %s, want %s", test.text, got, test.want)
+		}
+	}
+	if got := tracker.Advance(nil); got.Offset != 26 || got.Column != 5 {
+		t.Errorf("Advance() = %+v, want offset 26 and column 5", got)
+	}
+	if got := (Position{}).String(); got != "-" {
+		t.Errorf("String() of an unknown position = %s, want -", got)
+	}
+}
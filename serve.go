@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/sjmudd/mysql-variables-parser/format"
+	"github.com/sjmudd/mysql-variables-parser/server"
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// read a catalog: the JSON written by parse --format=json, or a page
+func readCatalog(page pageFlags, filename string) (*table.Table, error) {
+	if filepath.Ext(filename) != ".json" {
+		return page.parsePage(filename)
+	}
+	fi, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close()
+	return format.ReadJSON(fi)
+}
+
+// serve the variables over HTTP
+func serveCommand(args []string) int {
+	flags := newFlagSet("serve", "", `Serve the variables of each catalog, one per MySQL version, as JSON over HTTP:
+
+  GET  /variables                    the variables and their versions, ?q= to search
+  GET  /variables/{name}             a variable in ?version=, by default the latest
+  GET  /variables/{name}/versions    a variable in every version
+  GET  /diff?from=5.6&to=5.7         the changes between two versions
+  POST /lint                         check the my.cnf in the body against ?version=`)
+	var catalogs stringList
+	flags.Var(&catalogs, "catalog", "A catalog to serve, may be repeated: a page, or a .json file written by parse --format=json")
+	addr := flags.String("addr", "localhost:8080", "Address to listen on")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	switch {
+	case flags.NArg() > 0:
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	case len(catalogs) == 0:
+		return usageError(flags, "at least one catalog is needed")
+	case *page.version != "" && len(catalogs) > 1:
+		return usageError(flags, "--version can only be used with a single catalog")
+	}
+
+	tables := make([]*table.Table, 0, len(catalogs))
+	for _, filename := range catalogs {
+		t, err := readCatalog(page, filename)
+		if err != nil {
+			return failure("Failed to read %s: %v", filename, err)
+		}
+		if *page.version != "" {
+			t.SetVersion(*page.version)
+		}
+		tables = append(tables, t)
+	}
+	s, err := server.New(tables)
+	if err != nil {
+		return failure("Failed to load the catalogs: %v", err)
+	}
+	if err := http.ListenAndServe(*addr, s); err != nil {
+		return failure("Failed to serve on %s: %v", *addr, err)
+	}
+	return exitOK
+}
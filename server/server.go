@@ -0,0 +1,253 @@
+// Package server serves the variables of one or more MySQL versions as a
+// JSON API. Every successful response has an ETag so clients can cache them.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/diff"
+	"github.com/sjmudd/mysql-variables-parser/lookup"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/validate"
+)
+
+// the largest option file accepted by /lint
+const maxLintSize = 1 << 20
+
+// Server is the http.Handler of the API
+type Server struct {
+	tables   []*table.Table // oldest first
+	versions map[string]*table.Table
+	mux      *http.ServeMux
+}
+
+// Variable is a variable as documented in a version
+type Variable struct {
+	Version string `json:"version"`
+	table.Record
+}
+
+// the body of an error response
+type errorResponse struct {
+	Error       string   `json:"error"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// New returns the server of the tables, one per MySQL version
+func New(tables []*table.Table) (*Server, error) {
+	if len(tables) == 0 {
+		return nil, errors.New("no catalogs to serve")
+	}
+	s := &Server{
+		tables:   lookup.Sort(tables),
+		versions: make(map[string]*table.Table),
+		mux:      http.NewServeMux(),
+	}
+	for _, t := range s.tables {
+		if t.Version() == "" {
+			return nil, fmt.Errorf("the MySQL version of table %s is not known", t.Name())
+		}
+		if _, found := s.versions[t.Version()]; found {
+			return nil, fmt.Errorf("MySQL %s is given more than once", t.Version())
+		}
+		s.versions[t.Version()] = t
+	}
+	s.mux.HandleFunc("/variables", s.variables)
+	s.mux.HandleFunc("/variables/", s.variable)
+	s.mux.HandleFunc("/diff", s.diff)
+	s.mux.HandleFunc("/lint", s.lint)
+	return s, nil
+}
+
+// ServeHTTP handles a request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// the table of the version given by the parameter, or the latest version
+func (s *Server) version(w http.ResponseWriter, r *http.Request, parameter string) (*table.Table, bool) {
+	version := r.URL.Query().Get(parameter)
+	if version == "" {
+		return s.tables[len(s.tables)-1], true
+	}
+	if t, found := s.versions[version]; found {
+		return t, true
+	}
+	writeError(w, r, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("unknown MySQL version '%s'", version)})
+	return nil, false
+}
+
+// GET /variables lists the variables and the versions documenting them.
+// ?q= limits them to those matching a substring, or a regular expression
+// with ?regex=true.
+func (s *Server) variables(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	matches, err := lookup.Search(s.tables, r.URL.Query().Get("q"), r.URL.Query().Get("regex") == "true")
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, r, matches)
+}
+
+// GET /variables/{name} returns the variable in the version given by
+// ?version=, by default the latest. GET /variables/{name}/versions returns
+// it in every version documenting it, oldest first.
+func (s *Server) variable(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/variables/")
+	all := strings.HasSuffix(name, "/versions")
+	name = strings.TrimSuffix(name, "/versions")
+	if name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	var found []Variable
+	if all {
+		for _, t := range s.tables {
+			if row, ok := t.Lookup(name); ok {
+				found = append(found, Variable{Version: t.Version(), Record: row.Record()})
+			}
+		}
+	} else if t, ok := s.version(w, r, "version"); !ok {
+		return
+	} else if row, ok := t.Lookup(name); ok {
+		found = append(found, Variable{Version: t.Version(), Record: row.Record()})
+	}
+	if len(found) == 0 {
+		writeError(w, r, http.StatusNotFound, errorResponse{
+			Error:       fmt.Sprintf("unknown variable '%s'", name),
+			Suggestions: lookup.Suggest(s.tables, name),
+		})
+		return
+	}
+	if all {
+		writeJSON(w, r, found)
+		return
+	}
+	writeJSON(w, r, found[0])
+}
+
+// GET /diff?from=5.6&to=5.7 returns the changes between the versions
+func (s *Server) diff(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	if r.URL.Query().Get("from") == "" || r.URL.Query().Get("to") == "" {
+		writeError(w, r, http.StatusBadRequest, errorResponse{Error: "the from and to versions are needed"})
+		return
+	}
+	from, ok := s.version(w, r, "from")
+	if !ok {
+		return
+	}
+	to, ok := s.version(w, r, "to")
+	if !ok {
+		return
+	}
+	changes := diff.Tables(from, to)
+	if changes == nil {
+		changes = []diff.Change{}
+	}
+	writeJSON(w, r, changes)
+}
+
+// POST /lint checks the option file in the body against the version given
+// by ?version=, by default the latest, returning the problems found
+func (s *Server) lint(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	t, ok := s.version(w, r, "version")
+	if !ok {
+		return
+	}
+	options, err := config.ParseOptionFile(http.MaxBytesReader(w, r.Body, maxLintSize))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	problems := validate.CheckOptions(t, options)
+	if problems == nil {
+		problems = []validate.Problem{}
+	}
+	writeJSON(w, r, problems)
+}
+
+// allow checks the method of the request, responding if it's not allowed.
+// HEAD is allowed with GET.
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method || (method == http.MethodGet && r.Method == http.MethodHead) {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, r, http.StatusMethodNotAllowed, errorResponse{Error: "method " + r.Method + " not allowed"})
+	return false
+}
+
+// the entity tag of a response body
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// does the If-None-Match header match the entity tag?
+func notModified(r *http.Request, tag string) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	for _, t := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == tag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON writes a successful response with its entity tag, or Not
+// Modified if the client has it already
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	write(w, r, http.StatusOK, v)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, r *http.Request, status int, e errorResponse) {
+	write(w, r, status, e)
+}
+
+// write the response as JSON
+func write(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+
+	w.Header().Set("Content-Type", "application/json")
+	if status == http.StatusOK {
+		tag := etag(body)
+		w.Header().Set("ETag", tag)
+		if notModified(r, tag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
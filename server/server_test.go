@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/diff"
+	"github.com/sjmudd/mysql-variables-parser/lookup"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/validate"
+)
+
+func newTable(version string, rows ...table.Row) *table.Table {
+	t := table.NewTable("sysvar")
+	t.SetVersion(version)
+	for _, r := range rows {
+		t.AppendRow(r)
+	}
+	return t
+}
+
+func newRow(name, dynamic, defaultValue string) table.Row {
+	var r table.Row
+	r.SetSystemVariableName(name)
+	r.SetOptionFile("Yes")
+	r.SetSystemVar("Yes")
+	r.SetDynamic(dynamic)
+	r.SetDataType("Integer")
+	r.SetDefaultValue(defaultValue)
+	return r
+}
+
+func testServer(t *testing.T) *Server {
+	s, err := New([]*table.Table{
+		newTable("5.7", newRow("max_connections", "Yes", "151"), newRow("max_execution_time", "Yes", "0")),
+		newTable("5.6", newRow("max_connections", "Yes", "100"), newRow("query_cache_size", "Yes", "1048576")),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return s
+}
+
+// do the request returning the response, decoding the body into v
+func do(t *testing.T, s *Server, req *http.Request, v interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	if v != nil && w.Code != http.StatusNotModified {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: invalid json %q: %v", req.Method, req.URL, w.Body.String(), err)
+		}
+	}
+	return w
+}
+
+func get(t *testing.T, s *Server, url string, v interface{}) *httptest.ResponseRecorder {
+	return do(t, s, httptest.NewRequest(http.MethodGet, url, nil), v)
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("New() accepted no tables")
+	}
+	if _, err := New([]*table.Table{newTable("")}); err == nil {
+		t.Error("New() accepted a table without a version")
+	}
+	if _, err := New([]*table.Table{newTable("5.7"), newTable("5.7")}); err == nil {
+		t.Error("New() accepted the same version twice")
+	}
+}
+
+func TestVariables(t *testing.T) {
+	s := testServer(t)
+	var matches []lookup.Match
+	if w := get(t, s, "/variables?q=max_", &matches); w.Code != http.StatusOK || len(matches) != 2 ||
+		matches[0].Name != "max_connections" || len(matches[0].Versions) != 2 {
+		t.Errorf("GET /variables = %d %+v, want max_connections in 2 versions and max_execution_time", w.Code, matches)
+	}
+	if w := get(t, s, "/variables?q=(&regex=true", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("GET /variables with an invalid regex = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestVariable(t *testing.T) {
+	s := testServer(t)
+	var v Variable
+	if w := get(t, s, "/variables/max-connections", &v); w.Code != http.StatusOK || v.Version != "5.7" || v.DefaultValue != "151" {
+		t.Errorf("GET /variables/max-connections = %d %+v, want the 5.7 default 151", w.Code, v)
+	}
+	if w := get(t, s, "/variables/max_connections?version=5.6", &v); w.Code != http.StatusOK || v.Version != "5.6" || v.DefaultValue != "100" {
+		t.Errorf("GET /variables/max_connections?version=5.6 = %d %+v, want the 5.6 default 100", w.Code, v)
+	}
+
+	var versions []Variable
+	if w := get(t, s, "/variables/max_connections/versions", &versions); w.Code != http.StatusOK ||
+		len(versions) != 2 || versions[0].Version != "5.6" || versions[1].Version != "5.7" {
+		t.Errorf("GET /variables/max_connections/versions = %d %+v, want 5.6 and 5.7", w.Code, versions)
+	}
+
+	var e errorResponse
+	if w := get(t, s, "/variables/max_conections", &e); w.Code != http.StatusNotFound ||
+		len(e.Suggestions) != 1 || e.Suggestions[0] != "max_connections" {
+		t.Errorf("GET /variables/max_conections = %d %+v, want not found suggesting max_connections", w.Code, e)
+	}
+	if w := get(t, s, "/variables/query_cache_size?version=8.0", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET of an unknown version = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	s := testServer(t)
+	var changes []diff.Change
+	if w := get(t, s, "/diff?from=5.6&to=5.7", &changes); w.Code != http.StatusOK || len(changes) != 3 {
+		t.Errorf("GET /diff = %d %+v, want 3 changes", w.Code, changes)
+	}
+	if w := get(t, s, "/diff?from=5.6", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("GET /diff without to = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLint(t *testing.T) {
+	s := testServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/lint?version=5.6", strings.NewReader("[mysqld]\nmax_connections=1\nmax_execution_time=1\n"))
+	var problems []validate.Problem
+	if w := do(t, s, req, &problems); w.Code != http.StatusOK || len(problems) != 1 || problems[0].Line != 3 {
+		t.Errorf("POST /lint = %d %+v, want max_execution_time unknown in 5.6", w.Code, problems)
+	}
+	if w := get(t, s, "/lint", nil); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("GET /lint = %d, want %d allowing POST", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestETag(t *testing.T) {
+	s := testServer(t)
+	w := get(t, s, "/variables/max_connections", nil)
+	tag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || tag == "" {
+		t.Fatalf("GET = %d with ETag %q, want an ETag", w.Code, tag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/variables/max_connections", nil)
+	req.Header.Set("If-None-Match", tag)
+	if w := do(t, s, req, nil); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("GET with If-None-Match = %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/variables/max_connections?version=5.6", nil)
+	req.Header.Set("If-None-Match", tag)
+	if w := do(t, s, req, nil); w.Code != http.StatusOK {
+		t.Errorf("GET of another version with If-None-Match = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+// only successful responses can be cached
+func TestErrorHasNoETag(t *testing.T) {
+	w := get(t, testServer(t), "/variables/max_connection", nil)
+	if w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" {
+		t.Errorf("GET of an unknown variable = %d with ETag %q, want %d without an ETag", w.Code, w.Header().Get("ETag"), http.StatusNotFound)
+	}
+}
@@ -0,0 +1,4 @@
+package sqlitedb
+
+// the pure Go SQLite driver so no cgo is needed, registered as "sqlite"
+import _ "modernc.org/sqlite"
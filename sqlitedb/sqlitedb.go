@@ -0,0 +1,59 @@
+// Package sqlitedb writes the system variables into a SQLite database file
+// with the normalised layout so that several versions can be held together.
+package sqlitedb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// the name the driver is registered with
+const driverName = "sqlite"
+
+// Write adds the variables of the table to the database at path, creating
+// it if needed. Any variables already stored for the table's version are
+// replaced. Everything is done in a single transaction.
+func Write(path string, t *table.Table) error {
+	if t.Version() == "" {
+		return errors.New("the MySQL version is not known: it is needed to store the variables")
+	}
+
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := write(tx, t); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// create the normalised layout if needed and replace the variables of the table's version
+func write(tx *sql.Tx, t *table.Table) error {
+	for _, statement := range table.NormalisedSchema(table.SQLite) {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("creating schema: %v", err)
+		}
+	}
+
+	statements, err := t.NormalisedInserts(table.SQLite)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("adding the variables: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package sqlitedb
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func testTable(version, dynamic string) *table.Table {
+	t := table.NewTable("sysvar")
+	t.SetVersion(version)
+
+	var r table.Row
+	r.SetSystemVariableName("binlog_format")
+	r.SetSystemVar("Yes")
+	r.SetVarScope("Both")
+	r.SetDynamic(dynamic)
+	r.SetDataType("enumeration")
+	r.SetValidValues([]string{"ROW", "STATEMENT", "MIXED"})
+	t.AppendRow(r)
+
+	return t
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.db")
+
+	for _, tbl := range []*table.Table{
+		testTable("5.6", "Yes"),
+		testTable("5.7", "Yes"),
+		testTable("5.7", "No"), // replaces the previous 5.7 data
+	} {
+		if err := Write(path, tbl); err != nil {
+			t.Fatalf("Write(%s) failed: %v", tbl.Version(), err)
+		}
+	}
+
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	defer db.Close()
+
+	var versions, values int
+	var dynamic bool
+	if err := db.QueryRow(`SELECT COUNT(*) FROM variable_versions`).Scan(&versions); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM variable_permitted_values`).Scan(&values); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT dynamic FROM variable_versions
+WHERE version = '5.7' AND system_variable_name = 'binlog_format'`).Scan(&dynamic); err != nil {
+		t.Fatal(err)
+	}
+	if versions != 2 || values != 6 || dynamic {
+		t.Errorf("database has %d versions, %d values and dynamic %v, want 2, 6 and false", versions, values, dynamic)
+	}
+
+	var change string
+	if err := db.QueryRow(`SELECT change_type FROM variables_changed
+WHERE from_version = '5.6' AND to_version = '5.7'`).Scan(&change); err != nil {
+		t.Fatal(err)
+	}
+	if change != "changed" {
+		t.Errorf("binlog_format change from 5.6 to 5.7 is %s, want changed", change)
+	}
+
+	if err := Write(path, table.NewTable("no_version")); err == nil {
+		t.Errorf("Write() of a table without a version succeeded")
+	}
+}
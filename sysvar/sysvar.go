@@ -2,37 +2,146 @@ package sysvar
 
 import (
 	"fmt"
+	"log/slog"
+
+	"github.com/sjmudd/mysql-variables-parser/position"
 )
 
 type Types map[string]string
 
+// Values maps a sysvar name to a list of values
+type Values map[string][]string
+
+// Conflict is a value found twice which disagrees with the first found
+type Conflict struct {
+	Name             string
+	Field            string // e.g. type or default, or row for a row of the summary table
+	Previous         string
+	Value            string
+	PreviousPosition position.Position
+	Position         position.Position
+}
+
+// String describes the conflict prefixed by the position of the latest value
+func (c Conflict) String() string {
+	if c.Field == "row" {
+		return fmt.Sprintf("%s: the row of %s differs from the one at %s", c.Position, c.Name, c.PreviousPosition)
+	}
+	return fmt.Sprintf("%s: the %s of %s is %q but was %q at %s", c.Position, c.Field, c.Name, c.Value, c.Previous, c.PreviousPosition)
+}
+
 type Info struct {
-	name        string
-	types       Types
-	cmdline     Types
-	scope       Types
-	default_val Types
-	dynamic     Types
+	name         string
+	names        []string   // in the order their tables were found
+	conflicts    []Conflict // values found twice which disagree
+	position     position.Position
+	logger       *slog.Logger
+	positions    map[string]map[string]position.Position // of each field of each variable
+	types        Types
+	cmdline      Types
+	scope        Types
+	default_val  Types
+	dynamic      Types
+	min_value    Types
+	max_value    Types
+	valid_values Values
+	description  Types
 }
 
 func (i Info) LastSysvar() string {
 	return i.name
 }
 
+// SetLogger sets the logger warning of conflicting values, by default
+// slog.Default()
+func (i *Info) SetLogger(logger *slog.Logger) {
+	i.logger = logger
+}
+
+// the logger of the warnings
+func (i Info) log() *slog.Logger {
+	if i.logger == nil {
+		return slog.Default()
+	}
+	return i.logger
+}
+
+// SetPosition sets the position of the values saved next
+func (i *Info) SetPosition(p position.Position) {
+	i.position = p
+}
+
+// Position returns where the field of the variable was found, the
+// details table of the variable for the field "name"
+func (i Info) Position(name, field string) (position.Position, bool) {
+	p, found := i.positions[name][field]
+	return p, found
+}
+
+// record the position of the field of the current variable
+func (i *Info) setPosition(field string) {
+	if i.positions == nil {
+		i.positions = make(map[string]map[string]position.Position)
+	}
+	if i.positions[i.name] == nil {
+		i.positions[i.name] = make(map[string]position.Position)
+	}
+	i.positions[i.name][field] = i.position
+}
+
+// save the value of the field of the current variable, reporting a
+// conflict if a different value was found before
+func (i *Info) save(field string, values Types, value string) {
+	if previous, found := values[i.name]; found && previous != value {
+		c := Conflict{
+			Name:             i.name,
+			Field:            field,
+			Previous:         previous,
+			Value:            value,
+			PreviousPosition: i.positions[i.name][field],
+			Position:         i.position,
+		}
+		i.conflicts = append(i.conflicts, c)
+		i.log().Warn("conflicting values",
+			"variable", i.name,
+			"field", field,
+			"previous", previous,
+			"previous_position", c.PreviousPosition,
+			"value", value,
+			"position", c.Position)
+	}
+	values[i.name] = value
+	i.setPosition(field)
+}
+
 func (i *Info) SaveName(name string) {
 	i.name = name
+	if _, found := i.positions[name]["name"]; !found {
+		i.setPosition("name")
+	}
+	for _, n := range i.names {
+		if n == name {
+			return
+		}
+	}
+	i.names = append(i.names, name)
+}
+
+// Conflicts returns the values found twice which disagree
+func (i Info) Conflicts() []Conflict {
+	return i.conflicts
+}
+
+// Names returns the names of the variables with a details table
+func (i Info) Names() []string {
+	return i.names
 }
 
 func (i *Info) SaveCommandLine(cmd_line string) {
 	if i.cmdline == nil {
 		i.cmdline = make(Types)
 	}
-	if _, found := i.cmdline[i.name]; found && i.cmdline[cmd_line] != cmd_line {
-		fmt.Println("WARNING: already found a sysvar type for:", i.name)
-		fmt.Println("WARNING: current value:", i.cmdline[i.name])
-		fmt.Println("WARNING: new value:", cmd_line)
-	}
-	i.cmdline[cmd_line] = cmd_line
+	i.save("command line", i.cmdline, cmd_line)
 }
 
 // add to the name / type map.
@@ -45,24 +154,14 @@ func (i *Info) SaveType(name_type string) {
 	if i.types == nil {
 		i.types = make(Types)
 	}
-	if _, found := i.types[i.name]; found && i.types[name_type] != name_type {
-		fmt.Println("WARNING: already found a sysvar type for:", i.name)
-		fmt.Println("WARNING: current value:", i.types[i.name])
-		fmt.Println("WARNING: new value:", name_type)
-	}
-	i.types[name_type] = name_type
+	i.save("type", i.types, name_type)
 }
 
 func (i *Info) SaveScope(scope string) {
 	if i.scope == nil {
 		i.scope = make(Types)
 	}
-	if _, found := i.scope[i.name]; found && i.scope[i.name] != scope {
-		fmt.Println("WARNING: already found a sysvar scope for:", i.name)
-		fmt.Println("WARNING: current value:", i.scope[i.name])
-		fmt.Println("WARNING: new value:", scope)
-	}
-	i.scope[i.name] = scope
+	i.save("scope", i.scope, scope)
 }
 
 // save the default_val settings
@@ -70,12 +169,7 @@ func (i *Info) SaveDefault(default_value string) {
 	if i.default_val == nil {
 		i.default_val = make(Types)
 	}
-	if _, found := i.default_val[i.name]; found && i.default_val[i.name] != default_value {
-		fmt.Println("WARNING: already found a sysvar default_val for:", i.name)
-		fmt.Println("WARNING: current value:", i.default_val[i.name])
-		fmt.Println("WARNING: new value:", default_value)
-	}
-	i.scope[i.name] = default_value
+	i.save("default", i.default_val, default_value)
 }
 
 // set dynamic
@@ -83,12 +177,52 @@ func (i *Info) SaveDynamic(dynamic string) {
 	if i.dynamic == nil {
 		i.dynamic = make(Types)
 	}
-	if _, found := i.dynamic[i.name]; found && i.dynamic[i.name] != dynamic {
-		fmt.Println("WARNING: already found a sysvar dynamic for:", i.name)
-		fmt.Println("WARNING: current value:", i.dynamic[i.name])
-		fmt.Println("WARNING: new value:", dynamic)
+	i.save("dynamic", i.dynamic, dynamic)
+}
+
+// save the minimum permitted value
+func (i *Info) SaveMinimum(min_value string) {
+	if i.min_value == nil {
+		i.min_value = make(Types)
+	}
+	i.save("minimum", i.min_value, min_value)
+}
+
+// save the maximum permitted value
+func (i *Info) SaveMaximum(max_value string) {
+	if i.max_value == nil {
+		i.max_value = make(Types)
 	}
-	i.dynamic[i.name] = dynamic
+	i.save("maximum", i.max_value, max_value)
+}
+
+// add one of the valid values of an enumeration or set, ignoring duplicates
+func (i *Info) SaveValidValue(valid_value string) {
+	if i.valid_values == nil {
+		i.valid_values = make(Values)
+	}
+	for _, v := range i.valid_values[i.name] {
+		if v == valid_value {
+			return
+		}
+	}
+	if len(i.valid_values[i.name]) == 0 {
+		i.setPosition("valid values")
+	}
+	i.valid_values[i.name] = append(i.valid_values[i.name], valid_value)
+}
+
+// save the description of the variable, the paragraph following its
+// details table. Only the first found is kept.
+func (i *Info) SaveDescription(description string) {
+	if i.description == nil {
+		i.description = make(Types)
+	}
+	if _, found := i.description[i.name]; found {
+		return
+	}
+	i.description[i.name] = description
+	i.setPosition("description")
 }
 
 func (i *Info) Defaults() Types {
@@ -107,3 +241,19 @@ func (i *Info) CmdLines() Types {
 func (i *Info) Dynamics() Types {
 	return i.dynamic
 }
+
+func (i *Info) Minimums() Types {
+	return i.min_value
+}
+
+func (i *Info) Maximums() Types {
+	return i.max_value
+}
+
+func (i *Info) ValidValues() Values {
+	return i.valid_values
+}
+
+func (i *Info) Descriptions() Types {
+	return i.description
+}
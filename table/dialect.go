@@ -0,0 +1,315 @@
+// SQL dialects used to generate the table
+package table
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/util"
+)
+
+// ColumnKind is the kind of data held in a column
+type ColumnKind int
+
+// The column kinds used by the table layout
+const (
+	VarcharColumn ColumnKind = iota // a string with a maximum length
+	TextColumn                      // a string with no practical maximum length
+	BooleanColumn
+	EnumColumn // one of a list of strings
+	IntegerColumn
+)
+
+// Dialect generates the SQL for a particular database
+type Dialect interface {
+	// Name returns the name used to select the dialect
+	Name() string
+	// QuoteIdentifier returns a quoted table or column name
+	QuoteIdentifier(name string) string
+	// QuoteString returns a quoted string literal
+	QuoteString(s string) string
+	// Boolean returns a boolean literal
+	Boolean(b bool) string
+	// ColumnType returns the type of the named column. size is the maximum
+	// length of a varchar and values the permitted values of an enum.
+	ColumnType(name string, kind ColumnKind, size int, values []string) string
+	// TableOptions returns anything needed after the closing ) of CREATE TABLE
+	TableOptions() string
+	// Upsert returns the clause added to an INSERT statement so that an
+	// existing row with the same key is updated. If there are no columns
+	// other than the key the existing row is kept.
+	Upsert(key string, columns []string) string
+	// NullSafeEqual returns a comparison of a and b which is true if both are NULL
+	NullSafeEqual(a, b string) string
+	// CreateIndex returns the statement creating the index if it doesn't
+	// exist, or an empty string if the database creates it itself.
+	CreateIndex(index, table string, columns ...string) string
+	// Replace returns the start of an INSERT statement which replaces an
+	// existing row with the same key and any clause needed after the values.
+	Replace(key string, columns []string) (verb, clause string)
+	// InsertIgnore returns the start of an INSERT statement which keeps an
+	// existing row with the same key and any clause needed after the values.
+	InsertIgnore(key string) (verb, clause string)
+	// BeginTransaction returns the statement starting a transaction
+	BeginTransaction() string
+}
+
+// quote the column names for use in a column list
+func quoteColumns(d Dialect, columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, c := range columns {
+		quoted = append(quoted, d.QuoteIdentifier(c))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quote the values for use in an IN list or enum definition
+func quoteValues(d Dialect, values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, d.QuoteString(v))
+	}
+	return strings.Join(quoted, ",")
+}
+
+// MySQL, the dialect used by default
+type mysqlDialect struct {
+	noBackslashEscapes bool // for a server using the NO_BACKSLASH_ESCAPES sql_mode
+}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func (d mysqlDialect) QuoteString(s string) string {
+	if d.noBackslashEscapes {
+		return util.QuoteStandardString(s)
+	}
+	return util.QuoteString(s)
+}
+
+func (mysqlDialect) Boolean(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (d mysqlDialect) ColumnType(name string, kind ColumnKind, size int, values []string) string {
+	switch kind {
+	case TextColumn:
+		return "text"
+	case BooleanColumn:
+		return "BOOLEAN"
+	case EnumColumn:
+		return "ENUM(" + quoteValues(d, values) + ")"
+	case IntegerColumn:
+		return "int"
+	}
+	return fmt.Sprintf("varchar(%d)", size)
+}
+
+func (mysqlDialect) TableOptions() string { return " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4" }
+
+func (d mysqlDialect) Upsert(key string, columns []string) string {
+	updates := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if c != key {
+			updates = append(updates, d.QuoteIdentifier(c)+"=VALUES("+d.QuoteIdentifier(c)+")")
+		}
+	}
+	if len(updates) == 0 {
+		return " ON DUPLICATE KEY UPDATE " + d.QuoteIdentifier(key) + "=" + d.QuoteIdentifier(key)
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ",")
+}
+
+func (mysqlDialect) NullSafeEqual(a, b string) string { return a + " <=> " + b }
+
+// MySQL has no CREATE INDEX IF NOT EXISTS but creates the indexes needed by
+// foreign keys itself
+func (mysqlDialect) CreateIndex(index, table string, columns ...string) string { return "" }
+
+func (mysqlDialect) Replace(key string, columns []string) (string, string) {
+	return "REPLACE INTO ", ""
+}
+
+func (mysqlDialect) InsertIgnore(key string) (string, string) { return "INSERT IGNORE INTO ", "" }
+
+func (mysqlDialect) BeginTransaction() string { return "START TRANSACTION" }
+
+// PostgreSQL has no inline enum type so a CHECK constraint is used instead
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgresql" }
+
+func (postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// with standard_conforming_strings, the default since 9.1, a backslash is
+// not special and a NUL can't be stored so is dropped
+func (postgresDialect) QuoteString(s string) string {
+	return util.QuoteStandardString(strings.Replace(s, "\x00", "", -1))
+}
+
+func (postgresDialect) Boolean(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (d postgresDialect) ColumnType(name string, kind ColumnKind, size int, values []string) string {
+	switch kind {
+	case TextColumn:
+		return "text"
+	case BooleanColumn:
+		return "boolean"
+	case EnumColumn:
+		return fmt.Sprintf("varchar(%d) CHECK (%s IN (%s))", enumSize(values), d.QuoteIdentifier(name), quoteValues(d, values))
+	case IntegerColumn:
+		return "integer"
+	}
+	return fmt.Sprintf("varchar(%d)", size)
+}
+
+func (postgresDialect) TableOptions() string { return "" }
+
+func (d postgresDialect) Upsert(key string, columns []string) string {
+	return onConflict(d, key, columns)
+}
+
+func (postgresDialect) NullSafeEqual(a, b string) string { return a + " IS NOT DISTINCT FROM " + b }
+
+func (d postgresDialect) CreateIndex(index, table string, columns ...string) string {
+	return createIndex(d, index, table, columns)
+}
+
+// there is no REPLACE so existing rows are updated, which has the same effect
+// as the table has no other unique keys
+func (d postgresDialect) Replace(key string, columns []string) (string, string) {
+	return "INSERT INTO ", d.Upsert(key, columns)
+}
+
+func (d postgresDialect) InsertIgnore(key string) (string, string) {
+	return "INSERT INTO ", d.Upsert(key, nil)
+}
+
+func (postgresDialect) BeginTransaction() string { return "BEGIN" }
+
+// SQLite has dynamic typing so only the type affinity is given.
+// Booleans are stored as integers.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (sqliteDialect) QuoteString(s string) string { return util.QuoteStandardString(s) }
+
+func (sqliteDialect) Boolean(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func (d sqliteDialect) ColumnType(name string, kind ColumnKind, size int, values []string) string {
+	switch kind {
+	case BooleanColumn, IntegerColumn:
+		return "INTEGER"
+	case EnumColumn:
+		return "TEXT CHECK (" + d.QuoteIdentifier(name) + " IN (" + quoteValues(d, values) + "))"
+	}
+	return "TEXT"
+}
+
+func (sqliteDialect) TableOptions() string { return "" }
+
+func (d sqliteDialect) Upsert(key string, columns []string) string {
+	return onConflict(d, key, columns)
+}
+
+func (sqliteDialect) NullSafeEqual(a, b string) string { return a + " IS " + b }
+
+func (d sqliteDialect) CreateIndex(index, table string, columns ...string) string {
+	return createIndex(d, index, table, columns)
+}
+
+func (sqliteDialect) Replace(key string, columns []string) (string, string) {
+	return "INSERT OR REPLACE INTO ", ""
+}
+
+func (sqliteDialect) InsertIgnore(key string) (string, string) { return "INSERT OR IGNORE INTO ", "" }
+
+func (sqliteDialect) BeginTransaction() string { return "BEGIN TRANSACTION" }
+
+// the upsert clause used by PostgreSQL and SQLite
+func onConflict(d Dialect, key string, columns []string) string {
+	updates := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if c != key {
+			updates = append(updates, d.QuoteIdentifier(c)+"=excluded."+d.QuoteIdentifier(c))
+		}
+	}
+	if len(updates) == 0 {
+		return " ON CONFLICT (" + d.QuoteIdentifier(key) + ") DO NOTHING"
+	}
+	return " ON CONFLICT (" + d.QuoteIdentifier(key) + ") DO UPDATE SET " + strings.Join(updates, ",")
+}
+
+// the CREATE INDEX statement used by PostgreSQL and SQLite
+func createIndex(d Dialect, index, table string, columns []string) string {
+	return "CREATE INDEX IF NOT EXISTS " + d.QuoteIdentifier(index) + " ON " + d.QuoteIdentifier(table) + " (" + quoteColumns(d, columns) + ")"
+}
+
+// the length of the longest value
+func enumSize(values []string) int {
+	size := 1
+	for _, v := range values {
+		if len(v) > size {
+			size = len(v)
+		}
+	}
+	return size
+}
+
+var dialects = map[string]Dialect{
+	"mysql":      mysqlDialect{},
+	"postgresql": postgresDialect{},
+	"sqlite":     sqliteDialect{},
+}
+
+// MySQL is the dialect used unless another is chosen
+var MySQL Dialect = mysqlDialect{}
+
+// SQLite is the dialect of the SQLite database files written by --output
+var SQLite Dialect = sqliteDialect{}
+
+// Dialects returns the names of the supported dialects
+func Dialects() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupDialect returns the named dialect. postgres is accepted for postgresql.
+func LookupDialect(name string) (Dialect, error) {
+	name = strings.ToLower(name)
+	if name == "postgres" {
+		name = "postgresql"
+	}
+	if d, found := dialects[name]; found {
+		return d, nil
+	}
+	return nil, fmt.Errorf("unknown dialect '%s', expected one of: %s", name, strings.Join(Dialects(), ", "))
+}
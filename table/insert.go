@@ -0,0 +1,69 @@
+// extended INSERT statements like those of mysqldump
+package table
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetMaxStatementSize sets the largest size in bytes of an INSERT statement.
+// Rows are grouped into multi-row INSERTs up to this size. 0, the default,
+// gives one row per INSERT.
+func (t *Table) SetMaxStatementSize(size int) {
+	t.maxStatementSize = size
+}
+
+// SetLockTables selects whether the table is locked while the rows are inserted
+func (t *Table) SetLockTables(lock bool) {
+	t.lockTables = lock
+}
+
+// SetDisableKeys selects whether the keys are disabled while the rows are inserted
+func (t *Table) SetDisableKeys(disable bool) {
+	t.disableKeys = disable
+}
+
+// extendedInserts returns the INSERT statements for the values. Each statement
+// has as many rows as fit in max bytes, but always at least one. A max of 0
+// gives one row per statement.
+func extendedInserts(prefix, clause string, values []string, max int) []string {
+	statements := []string{}
+	s := ""
+	for _, v := range values {
+		if s != "" && (max <= 0 || len(s)+1+len(v)+len(clause)+1 > max) {
+			statements = append(statements, s+clause+";")
+			s = ""
+		}
+		if s == "" {
+			s = prefix + v
+		} else {
+			s += "," + v
+		}
+	}
+	if s != "" {
+		statements = append(statements, s+clause+";")
+	}
+	return statements
+}
+
+// lock the table and disable its keys as mysqldump does. These are MySQL only.
+func (t Table) lockTable(w io.Writer) {
+	name := t.dialect.QuoteIdentifier(t.name)
+	if t.lockTables {
+		fmt.Fprintf(w, "LOCK TABLES %s WRITE;\n", name)
+	}
+	if t.disableKeys {
+		fmt.Fprintf(w, "/*!40000 ALTER TABLE %s DISABLE KEYS */;\n", name)
+	}
+}
+
+// enable the keys and unlock the table
+func (t Table) unlockTable(w io.Writer) {
+	name := t.dialect.QuoteIdentifier(t.name)
+	if t.disableKeys {
+		fmt.Fprintf(w, "/*!40000 ALTER TABLE %s ENABLE KEYS */;\n", name)
+	}
+	if t.lockTables {
+		fmt.Fprintln(w, "UNLOCK TABLES;")
+	}
+}
@@ -0,0 +1,54 @@
+package table
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtendedInserts(t *testing.T) {
+	values := []string{"(1)", "(2)", "(3)", "(4444444444)"}
+	tests := []struct {
+		max  int
+		want []string
+	}{
+		{0, []string{"I (1);", "I (2);", "I (3);", "I (4444444444);"}},
+		{14, []string{"I (1),(2),(3);", "I (4444444444);"}},
+		{13, []string{"I (1),(2);", "I (3);", "I (4444444444);"}},
+		{1000, []string{"I (1),(2),(3),(4444444444);"}},
+	}
+	for _, test := range tests {
+		if got := extendedInserts("I ", "", values, test.max); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("extendedInserts(%d) = %q, want %q", test.max, got, test.want)
+		}
+	}
+	if got := extendedInserts("I ", "", nil, 10); len(got) != 0 {
+		t.Errorf("extendedInserts() with no values = %q, want none", got)
+	}
+}
+
+func TestLockTables(t *testing.T) {
+	tbl := NewTable("sysvar")
+	for _, name := range []string{"autocommit", "big_tables"} {
+		var r Row
+		r.SetSystemVariableName(name)
+		r.SetDynamic("Yes")
+		tbl.AppendRow(r)
+	}
+	tbl.SetMaxStatementSize(1 << 20)
+	tbl.SetLockTables(true)
+	tbl.SetDisableKeys(true)
+
+	var b bytes.Buffer
+	tbl.InsertStatements(&b)
+	want := "-- Insert rows\n" +
+		"LOCK TABLES `sysvar` WRITE;\n" +
+		"/*!40000 ALTER TABLE `sysvar` DISABLE KEYS */;\n" +
+		"INSERT INTO `sysvar` ("
+	if got := b.String(); !strings.HasPrefix(got, want) ||
+		!strings.Contains(got, "),('big_tables',") ||
+		!strings.HasSuffix(got, ";\n/*!40000 ALTER TABLE `sysvar` ENABLE KEYS */;\nUNLOCK TABLES;\n") {
+		t.Errorf("InsertStatements() = %s, want one locked INSERT", got)
+	}
+}
@@ -0,0 +1,66 @@
+// how the generated SQL treats an existing table and rows
+package table
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLMode selects how the generated SQL treats an existing table and rows
+type SQLMode int
+
+// The SQL modes. Only CreateMode drops an existing table.
+const (
+	CreateMode            SQLMode = iota // drop and create the table then insert the rows
+	CreateIfNotExistsMode                // create the table if needed then insert the rows
+	ReplaceMode                          // as CreateIfNotExistsMode but replace existing rows
+	UpsertMode                           // as CreateIfNotExistsMode but update existing rows
+	InsertIgnoreMode                     // as CreateIfNotExistsMode but keep existing rows
+)
+
+var sqlModeNames = []string{"create", "create-if-not-exists", "replace", "upsert", "insert-ignore"}
+
+// String returns the name used to select the mode
+func (m SQLMode) String() string {
+	if int(m) < len(sqlModeNames) {
+		return sqlModeNames[m]
+	}
+	return fmt.Sprintf("SQLMode(%d)", int(m))
+}
+
+// SQLModes returns the names of the SQL modes
+func SQLModes() []string {
+	return append([]string(nil), sqlModeNames...)
+}
+
+// LookupSQLMode returns the named SQL mode
+func LookupSQLMode(name string) (SQLMode, error) {
+	for i, n := range sqlModeNames {
+		if strings.EqualFold(name, n) {
+			return SQLMode(i), nil
+		}
+	}
+	return CreateMode, fmt.Errorf("unknown sql mode '%s', expected one of: %s", name, strings.Join(sqlModeNames, ", "))
+}
+
+// the start of the CREATE TABLE statement for the mode, dropping the table first if needed
+func createTableHeader(mode SQLMode, quotedName string) string {
+	if mode == CreateMode {
+		return "-- Create table entry\nDROP TABLE IF EXISTS " + quotedName + ";\nCREATE TABLE " + quotedName
+	}
+	return "-- Create table entry\nCREATE TABLE IF NOT EXISTS " + quotedName
+}
+
+// insertSyntax returns the start of the INSERT statement for the mode and the
+// clause which follows the values, if any
+func insertSyntax(d Dialect, mode SQLMode, key string, columns []string) (verb, clause string) {
+	switch mode {
+	case ReplaceMode:
+		return d.Replace(key, columns)
+	case UpsertMode:
+		return "INSERT INTO ", d.Upsert(key, columns)
+	case InsertIgnoreMode:
+		return d.InsertIgnore(key)
+	}
+	return "INSERT INTO ", ""
+}
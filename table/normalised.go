@@ -0,0 +1,226 @@
+// the normalised table layout which holds every version in one database
+package table
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// the tables and views of the normalised layout
+const (
+	versionsTable         = "mysql_versions"
+	variablesTable        = "variables"
+	variableVersionsTable = "variable_versions"
+	permittedValuesTable  = "variable_permitted_values"
+	consecutiveView       = "consecutive_versions"
+	changedView           = "variables_changed"
+	versionColumn         = "version"
+	orderColumn           = "version_order"
+)
+
+// the columns of variable_versions which describe the variable: the valid
+// values are held in variable_permitted_values
+func attributeColumns() []column {
+	attributes := make([]column, 0, len(columns))
+	for _, c := range columns {
+		if c.name != keyColumn && c.name != "valid_values" {
+			attributes = append(attributes, c)
+		}
+	}
+	return attributes
+}
+
+// VersionOrder returns a number which sorts versions such as 5.7 and 8.0
+// in release order, or 0 if the version can't be parsed
+func VersionOrder(version string) int {
+	order := 0
+	parts := strings.SplitN(version, ".", 3)
+	for i := 0; i < 3; i++ {
+		order *= 1000
+		if i < len(parts) {
+			n, err := strconv.Atoi(parts[i])
+			if err != nil {
+				return 0
+			}
+			order += n
+		}
+	}
+	return order
+}
+
+// NormalisedSchema returns the statements creating the normalised layout,
+// its indexes and views. Existing tables are kept so that several versions
+// can be loaded one after the other.
+func NormalisedSchema(d Dialect) []string {
+	q := d.QuoteIdentifier
+	name := q(keyColumn)
+	version := q(versionColumn)
+
+	createTable := func(table string, definitions ...string) string {
+		return "CREATE TABLE IF NOT EXISTS " + q(table) + " (\n    " + strings.Join(definitions, ",\n    ") + "\n)" + d.TableOptions()
+	}
+
+	attributes := []string{
+		name + " " + d.ColumnType(keyColumn, VarcharColumn, 128, nil) + " NOT NULL",
+		version + " " + d.ColumnType(versionColumn, VarcharColumn, 16, nil) + " NOT NULL",
+	}
+	for _, c := range attributeColumns() {
+		attributes = append(attributes, columnDefinition(d, c))
+	}
+	attributes = append(attributes,
+		"PRIMARY KEY ("+name+", "+version+")",
+		"FOREIGN KEY ("+name+") REFERENCES "+q(variablesTable)+" ("+name+")",
+		"FOREIGN KEY ("+version+") REFERENCES "+q(versionsTable)+" ("+version+")",
+	)
+
+	statements := []string{
+		createTable(versionsTable,
+			version+" "+d.ColumnType(versionColumn, VarcharColumn, 16, nil)+" NOT NULL",
+			q(orderColumn)+" "+d.ColumnType(orderColumn, IntegerColumn, 0, nil)+" NOT NULL",
+			"PRIMARY KEY ("+version+")",
+		),
+		createTable(variablesTable,
+			name+" "+d.ColumnType(keyColumn, VarcharColumn, 128, nil)+" NOT NULL",
+			"PRIMARY KEY ("+name+")",
+		),
+		createTable(variableVersionsTable, attributes...),
+		createTable(permittedValuesTable,
+			name+" "+d.ColumnType(keyColumn, VarcharColumn, 128, nil)+" NOT NULL",
+			version+" "+d.ColumnType(versionColumn, VarcharColumn, 16, nil)+" NOT NULL",
+			q("position")+" "+d.ColumnType("position", IntegerColumn, 0, nil)+" NOT NULL",
+			q("value")+" "+d.ColumnType("value", TextColumn, 0, nil)+" NOT NULL",
+			"PRIMARY KEY ("+name+", "+version+", "+q("position")+")",
+			"FOREIGN KEY ("+name+", "+version+") REFERENCES "+q(variableVersionsTable)+" ("+name+", "+version+")",
+		),
+		createTable(provenanceTable, provenanceDefinitions(d)...),
+	}
+	if index := d.CreateIndex(variableVersionsTable+"_version", variableVersionsTable, versionColumn); index != "" {
+		statements = append(statements, index)
+	}
+
+	// variables_changed depends on consecutive_versions so is dropped first
+	return append(statements,
+		"DROP VIEW IF EXISTS "+q(changedView),
+		"DROP VIEW IF EXISTS "+q(consecutiveView),
+		"CREATE VIEW "+q(consecutiveView)+" AS\n"+consecutiveVersionsQuery(d),
+		"CREATE VIEW "+q(changedView)+" AS\n"+variablesChangedQuery(d),
+	)
+}
+
+// each version with the one released after it
+func consecutiveVersionsQuery(d Dialect) string {
+	q := d.QuoteIdentifier
+	return fmt.Sprintf(`SELECT p.%[1]s AS from_version, n.%[1]s AS to_version
+FROM %[2]s p
+JOIN %[2]s n ON n.%[3]s = (SELECT MIN(m.%[3]s) FROM %[2]s m WHERE m.%[3]s > p.%[3]s)`,
+		q(versionColumn), q(versionsTable), q(orderColumn))
+}
+
+// the variables added, removed or with different attributes in each
+// version compared with the previous one
+func variablesChangedQuery(d Dialect) string {
+	q := d.QuoteIdentifier
+	name := q(keyColumn)
+	version := q(versionColumn)
+	vv := q(variableVersionsTable)
+
+	same := make([]string, 0, len(columns))
+	for _, c := range attributeColumns() {
+		if c.name == descriptionColumn || c.name == sourceColumn {
+			continue // differ between versions without the variable changing
+		}
+		same = append(same, "("+d.NullSafeEqual("o."+q(c.name), "n."+q(c.name))+")")
+	}
+
+	missing := func(change, present, absent, presentVersion, absentVersion string) string {
+		return fmt.Sprintf(`SELECT c.from_version, c.to_version, %[1]s.%[2]s, '%[3]s' AS change_type
+FROM %[4]s c
+JOIN %[5]s %[1]s ON %[1]s.%[6]s = c.%[7]s
+WHERE NOT EXISTS (SELECT 1 FROM %[5]s %[8]s WHERE %[8]s.%[6]s = c.%[9]s AND %[8]s.%[2]s = %[1]s.%[2]s)`,
+			present, name, change, q(consecutiveView), vv, version, presentVersion, absent, absentVersion)
+	}
+
+	return missing("added", "n", "o", "to_version", "from_version") + "\nUNION ALL\n" +
+		missing("removed", "o", "n", "from_version", "to_version") + "\nUNION ALL\n" +
+		fmt.Sprintf(`SELECT c.from_version, c.to_version, n.%[1]s, 'changed' AS change_type
+FROM %[2]s c
+JOIN %[3]s o ON o.%[4]s = c.from_version
+JOIN %[3]s n ON n.%[4]s = c.to_version AND n.%[1]s = o.%[1]s
+WHERE NOT (%[5]s)`, name, q(consecutiveView), vv, version, strings.Join(same, " AND "))
+}
+
+// NormalisedInserts returns the statements which store the variables of the
+// table in the normalised layout, replacing any already stored for its version.
+func (t Table) NormalisedInserts(d Dialect) ([]string, error) {
+	if t.version == "" {
+		return nil, errors.New("the MySQL version is not known: it is needed for the normalised layout")
+	}
+	q := d.QuoteIdentifier
+	version := d.QuoteString(t.version)
+
+	insert := func(table string, names []string, values []string) string {
+		quoted := make([]string, 0, len(names))
+		for _, n := range names {
+			quoted = append(quoted, q(n))
+		}
+		return "INSERT INTO " + q(table) + " (" + strings.Join(quoted, ",") + ") VALUES (" + strings.Join(values, ",") + ")"
+	}
+
+	statements := []string{
+		insert(versionsTable, []string{versionColumn, orderColumn}, []string{version, strconv.Itoa(VersionOrder(t.version))}) +
+			d.Upsert(versionColumn, []string{versionColumn, orderColumn}),
+		"DELETE FROM " + q(permittedValuesTable) + " WHERE " + q(versionColumn) + " = " + version,
+		"DELETE FROM " + q(variableVersionsTable) + " WHERE " + q(versionColumn) + " = " + version,
+		"DELETE FROM " + q(provenanceTable) + " WHERE " + q(versionColumn) + " = " + version,
+	}
+	if p, found := t.Provenance(); found {
+		names := append([]string{versionColumn}, ProvenanceColumns()...)
+		statements = append(statements, insert(provenanceTable, names, append([]string{version}, p.literals(d)...)))
+	}
+
+	attributes := attributeColumns()
+	names := []string{keyColumn, versionColumn}
+	for _, c := range attributes {
+		names = append(names, c.name)
+	}
+	for _, rec := range t.Records() {
+		name := d.QuoteString(rec.Name)
+		values := []string{name, version}
+		for _, c := range attributes {
+			values = append(values, literal(d, c.value(rec)))
+		}
+		statements = append(statements,
+			insert(variablesTable, []string{keyColumn}, []string{name})+d.Upsert(keyColumn, []string{keyColumn}),
+			insert(variableVersionsTable, names, values),
+		)
+		for i, v := range rec.ValidValues {
+			statements = append(statements, insert(permittedValuesTable,
+				[]string{keyColumn, versionColumn, "position", "value"},
+				[]string{name, version, strconv.Itoa(i), d.QuoteString(v)}))
+		}
+	}
+	return statements, nil
+}
+
+// NormalisedDump writes the normalised layout and the table's variables to w
+func (t Table) NormalisedDump(w io.Writer) error {
+	inserts, err := t.NormalisedInserts(t.sqlDialect())
+	if err != nil {
+		return err
+	}
+	t.provenanceComment(w)
+	fmt.Fprintln(w, "-- Normalised layout")
+	for _, s := range NormalisedSchema(t.sqlDialect()) {
+		fmt.Fprintln(w, s+";")
+	}
+	t.begin(w)
+	fmt.Fprintln(w, "-- Variables of MySQL "+t.version)
+	for _, s := range inserts {
+		fmt.Fprintln(w, s+";")
+	}
+	t.commit(w)
+	return nil
+}
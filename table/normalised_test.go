@@ -0,0 +1,59 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVersionOrder(t *testing.T) {
+	versions := []string{"5.0", "5.1", "5.5", "5.6", "5.7", "8.0", "8.0.11", "10.0"}
+	for i := 1; i < len(versions); i++ {
+		if VersionOrder(versions[i-1]) >= VersionOrder(versions[i]) {
+			t.Errorf("VersionOrder(%s) >= VersionOrder(%s)", versions[i-1], versions[i])
+		}
+	}
+	if got := VersionOrder("latest"); got != 0 {
+		t.Errorf("VersionOrder(latest) = %d, want 0", got)
+	}
+}
+
+func TestNormalisedDump(t *testing.T) {
+	tbl := NewTable("sysvar")
+	var r Row
+	r.SetSystemVariableName("binlog_format")
+	r.SetSystemVar("Yes")
+	r.SetVarScope("Both")
+	r.SetValidValues([]string{"ROW", "STATEMENT"})
+	tbl.AppendRow(r)
+	tbl.SetNormalised(true)
+
+	var b bytes.Buffer
+	if err := tbl.Dump(&b); err == nil {
+		t.Errorf("Dump() of a table without a version succeeded")
+	}
+
+	tbl.SetVersion("5.7")
+	b.Reset()
+	if err := tbl.Dump(&b); err != nil {
+		t.Fatalf("Dump() failed: %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS `variable_versions` (",
+		"    FOREIGN KEY (`version`) REFERENCES `mysql_versions` (`version`)",
+		"CREATE VIEW `variables_changed` AS",
+		"(o.`dynamic` <=> n.`dynamic`)",
+		"INSERT INTO `mysql_versions` (`version`,`version_order`) VALUES ('5.7',5007000) ON DUPLICATE KEY UPDATE `version_order`=VALUES(`version_order`);",
+		"DELETE FROM `variable_versions` WHERE `version` = '5.7';",
+		"INSERT INTO `variable_versions` (`system_variable_name`,`version`,`cmd_line`,`option_file`,`system_var`,`var_scope`,",
+		"VALUES ('binlog_format','5.7',1,'STATEMENT');",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Dump() = %s, missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "DROP TABLE") {
+		t.Errorf("Dump() of the normalised layout drops a table")
+	}
+}
@@ -0,0 +1,126 @@
+package table
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// the table of the normalised layout holding the provenance of each version
+const provenanceTable = "provenance"
+
+// Provenance describes where the variables of a table came from
+type Provenance struct {
+	Source        string    `json:"source"`         // the path or URL of the page
+	SHA256        string    `json:"sha256"`         // of the page as read
+	ManualVersion string    `json:"manual_version"` // found in the page title, empty if not found
+	Parsed        time.Time `json:"parsed"`
+	ToolVersion   string    `json:"tool_version"`
+	Rows          int       `json:"rows"`
+	Conflicts     int       `json:"conflicts"` // values found twice which disagree
+}
+
+// SetProvenance records where the variables came from. The counts are
+// taken from the table when the provenance is returned.
+func (t *Table) SetProvenance(p Provenance) {
+	t.provenance = &p
+}
+
+// AddConflicts counts values which disagree found before the rows reached
+// the table, e.g. when the table is read back from the JSON output
+func (t *Table) AddConflicts(n int) {
+	t.otherConflicts += n
+}
+
+// Provenance returns where the variables came from with the counts of
+// rows and conflicts. found is false if it was never set.
+func (t Table) Provenance() (p Provenance, found bool) {
+	if t.provenance == nil {
+		return Provenance{}, false
+	}
+	p = *t.provenance
+	p.Rows = len(t.Records())
+	p.Conflicts = len(t.conflicts) + t.otherConflicts
+	return p, true
+}
+
+// write the provenance as SQL comments, if known
+func (t Table) provenanceComment(w io.Writer) {
+	p, found := t.Provenance()
+	if !found {
+		return
+	}
+	oneLine := strings.NewReplacer("\n", " ", "\r", " ")
+	fmt.Fprintln(w, "-- Source: "+oneLine.Replace(p.Source))
+	fmt.Fprintln(w, "-- SHA-256: "+p.SHA256)
+	fmt.Fprintln(w, "-- Manual version: "+oneLine.Replace(p.ManualVersion))
+	fmt.Fprintln(w, "-- Parsed: "+p.Parsed.UTC().Format(time.RFC3339))
+	fmt.Fprintln(w, "-- Tool version: "+oneLine.Replace(p.ToolVersion))
+	fmt.Fprintf(w, "-- Rows: %d, conflicts: %d\n", p.Rows, p.Conflicts)
+}
+
+// the columns of the provenance table after the version
+var provenanceColumns = []struct {
+	name string
+	kind ColumnKind
+	size int
+}{
+	{"source", TextColumn, 0},
+	{"sha256", VarcharColumn, 64},
+	{"manual_version", VarcharColumn, 16},
+	{"parsed", VarcharColumn, 32},
+	{"tool_version", VarcharColumn, 64},
+	{"row_count", IntegerColumn, 0},
+	{"conflicts", IntegerColumn, 0},
+}
+
+// ProvenanceColumns returns the names of the columns holding a provenance
+func ProvenanceColumns() []string {
+	names := make([]string, 0, len(provenanceColumns))
+	for _, c := range provenanceColumns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// ProvenanceColumnDefinitions returns the definitions of the columns
+// holding a provenance as used in CREATE TABLE
+func ProvenanceColumnDefinitions(d Dialect) []string {
+	definitions := make([]string, 0, len(provenanceColumns))
+	for _, c := range provenanceColumns {
+		definitions = append(definitions, d.QuoteIdentifier(c.name)+" "+d.ColumnType(c.name, c.kind, c.size, nil))
+	}
+	return definitions
+}
+
+// the definitions of the columns and keys of the provenance table of the
+// normalised layout
+func provenanceDefinitions(d Dialect) []string {
+	version := d.QuoteIdentifier(versionColumn)
+	definitions := []string{version + " " + d.ColumnType(versionColumn, VarcharColumn, 16, nil) + " NOT NULL"}
+	definitions = append(definitions, ProvenanceColumnDefinitions(d)...)
+	return append(definitions,
+		"PRIMARY KEY ("+version+")",
+		"FOREIGN KEY ("+version+") REFERENCES "+d.QuoteIdentifier(versionsTable)+" ("+version+")",
+	)
+}
+
+// ColumnValues returns the values of the provenance columns in order
+func (p Provenance) ColumnValues() []interface{} {
+	return []interface{}{p.Source, p.SHA256, p.ManualVersion, p.Parsed.UTC().Format(time.RFC3339), p.ToolVersion, p.Rows, p.Conflicts}
+}
+
+// the values of the provenance columns as SQL literals
+func (p Provenance) literals(d Dialect) []string {
+	var literals []string
+	for _, v := range p.ColumnValues() {
+		switch v := v.(type) {
+		case string:
+			literals = append(literals, d.QuoteString(v))
+		default:
+			literals = append(literals, fmt.Sprint(v))
+		}
+	}
+	return literals
+}
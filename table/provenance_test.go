@@ -0,0 +1,86 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func provenanceTable57() *Table {
+	tbl := NewTable("sysvar")
+	for _, name := range []string{"autocommit", "basedir"} {
+		var r Row
+		r.SetSystemVariableName(name)
+		r.SetSystemVar("Yes")
+		tbl.AppendRow(r)
+	}
+	tbl.SetVersion("5.7")
+	tbl.SetProvenance(Provenance{
+		Source:        "https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html",
+		SHA256:        strings.Repeat("ab", 32),
+		ManualVersion: "5.7",
+		Parsed:        time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		ToolVersion:   "v1.0.0",
+	})
+	tbl.AddConflicts(1)
+	return tbl
+}
+
+func TestProvenance(t *testing.T) {
+	if _, found := NewTable("sysvar").Provenance(); found {
+		t.Errorf("Provenance() of a new table was found")
+	}
+	p, found := provenanceTable57().Provenance()
+	if !found || p.Rows != 2 || p.Conflicts != 1 {
+		t.Errorf("Provenance() = %+v, %v, want 2 rows and 1 conflict", p, found)
+	}
+}
+
+func TestProvenanceComment(t *testing.T) {
+	var b bytes.Buffer
+	if err := provenanceTable57().Dump(&b); err != nil {
+		t.Fatalf("Dump() failed: %v", err)
+	}
+	want := "-- Source: https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html\n" +
+		"-- SHA-256: " + strings.Repeat("ab", 32) + "\n" +
+		"-- Manual version: 5.7\n" +
+		"-- Parsed: 2024-05-01T12:00:00Z\n" +
+		"-- Tool version: v1.0.0\n" +
+		"-- Rows: 2, conflicts: 1\n"
+	if got := b.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("Dump() = %s, want it to start with %s", got, want)
+	}
+}
+
+func TestNormalisedProvenance(t *testing.T) {
+	tbl := provenanceTable57()
+	tbl.SetNormalised(true)
+	var b bytes.Buffer
+	if err := tbl.Dump(&b); err != nil {
+		t.Fatalf("Dump() failed: %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS `provenance` (",
+		"DELETE FROM `provenance` WHERE `version` = '5.7';",
+		"INSERT INTO `provenance` (`version`,`source`,`sha256`,`manual_version`,`parsed`,`tool_version`,`row_count`,`conflicts`) VALUES ('5.7','https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html','" +
+			strings.Repeat("ab", 32) + "','5.7','2024-05-01T12:00:00Z','v1.0.0',2,1);",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Dump() = %s, missing %q", got, want)
+		}
+	}
+}
+
+func TestCompatibleHasNoProvenanceComment(t *testing.T) {
+	tbl := provenanceTable57()
+	tbl.SetCompatible(true)
+	var b bytes.Buffer
+	if err := tbl.Dump(&b); err != nil {
+		t.Fatalf("Dump() failed: %v", err)
+	}
+	if got, want := b.String(), "-- New table:"; !strings.HasPrefix(got, want) {
+		t.Errorf("Dump() = %s, want it to start with %s", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package table
+
+// Record is a row with its values normalised, used by the output formats.
+// The fields are always present so every format has the same field set.
+// The description is empty if the page has none and the source is empty
+// unless the page was read from an archive.
+type Record struct {
+	Name              string   `json:"name"`
+	CmdLine           bool     `json:"cmd_line"`
+	OptionFile        bool     `json:"option_file"`
+	SystemVar         bool     `json:"system_var"`
+	Scope             string   `json:"scope"`
+	Dynamic           *bool    `json:"dynamic"` // nil if not known
+	CommandLineFormat string   `json:"command_line_format"`
+	DefaultValue      string   `json:"default_value"`
+	DataType          string   `json:"data_type"`
+	MinValue          string   `json:"min_value"`
+	MaxValue          string   `json:"max_value"`
+	ValidValues       []string `json:"valid_values"`
+	Description       string   `json:"description"`
+	Source            string   `json:"source"`
+}
+
+// RecordFields are the names of the record fields in output order
+var RecordFields = []string{
+	"name",
+	"cmd_line",
+	"option_file",
+	"system_var",
+	"scope",
+	"dynamic",
+	"command_line_format",
+	"default_value",
+	"data_type",
+	"min_value",
+	"max_value",
+	"valid_values",
+	"description",
+	"source",
+}
+
+// Record returns the row as a record
+func (r Row) Record() Record {
+	rec := Record{
+		Name:              normalise(r.system_variable_name),
+		Scope:             normalise(r.var_scope),
+		CommandLineFormat: normalise(r.command_line_format),
+		DefaultValue:      normalise(r.default_value),
+		DataType:          normalise(r.data_type),
+		MinValue:          normalise(r.min_value),
+		MaxValue:          normalise(r.max_value),
+		ValidValues:       append([]string{}, r.valid_values...),
+		Description:       r.description,
+		Source:            r.source,
+	}
+	rec.CmdLine, _ = yesNo(r.cmd_line)
+	rec.OptionFile, _ = yesNo(r.option_file)
+	rec.SystemVar, _ = yesNo(r.system_var)
+	if dynamic, known := yesNo(r.dynamic); known {
+		rec.Dynamic = &dynamic
+	}
+	return rec
+}
+
+// the Yes/No form of a flag used by the documentation
+func yesNoString(value bool) string {
+	if value {
+		return "Yes"
+	}
+	return "No"
+}
+
+// Row returns the row holding the record. Flags which were blank in the
+// documentation become No as the record doesn't keep them.
+func (rec Record) Row() Row {
+	r := Row{
+		system_variable_name: rec.Name,
+		cmd_line:             yesNoString(rec.CmdLine),
+		option_file:          yesNoString(rec.OptionFile),
+		system_var:           yesNoString(rec.SystemVar),
+		var_scope:            rec.Scope,
+		command_line_format:  rec.CommandLineFormat,
+		default_value:        rec.DefaultValue,
+		data_type:            rec.DataType,
+		min_value:            rec.MinValue,
+		max_value:            rec.MaxValue,
+		valid_values:         append([]string(nil), rec.ValidValues...),
+		description:          rec.Description,
+		source:               rec.Source,
+	}
+	if rec.Dynamic != nil {
+		r.dynamic = yesNoString(*rec.Dynamic)
+	}
+	return r
+}
@@ -3,7 +3,10 @@ package table
 
 import (
 	"fmt"
+	"io"
 	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/position"
 )
 
 type Row struct {
@@ -16,6 +19,12 @@ type Row struct {
 	command_line_format  string
 	default_value        string
 	data_type            string
+	min_value            string
+	max_value            string
+	valid_values         []string
+	description          string // the paragraph following the details table
+	source               string // where the row was found, e.g. the member of an archive
+	position             position.Position
 }
 
 func (r *Row) SetSystemVariableName(name string) {
@@ -36,6 +45,114 @@ func (r *Row) SetVarScope(name string) {
 func (r *Row) SetDynamic(name string) {
 	r.dynamic = name
 }
+func (r *Row) SetCommandLineFormat(name string) {
+	r.command_line_format = name
+}
+func (r *Row) SetDefaultValue(name string) {
+	r.default_value = name
+}
+func (r *Row) SetDataType(name string) {
+	r.data_type = name
+}
+func (r *Row) SetMinValue(name string) {
+	r.min_value = name
+}
+func (r *Row) SetMaxValue(name string) {
+	r.max_value = name
+}
+func (r *Row) SetValidValues(values []string) {
+	r.valid_values = values
+}
+
+// SetDescription records the description of the variable
+func (r *Row) SetDescription(description string) {
+	r.description = description
+}
+
+// Description returns the description of the variable, empty if not known
+func (r Row) Description() string {
+	return r.description
+}
+
+// SetSource records where the row was found
+func (r *Row) SetSource(source string) {
+	r.source = source
+}
+
+// SetPosition records where in the page the row was found
+func (r *Row) SetPosition(p position.Position) {
+	r.position = p
+}
+
+// Position returns where in the page the row was found
+func (r Row) Position() position.Position {
+	return r.position
+}
+
+// Source returns where the row was found, empty if not known
+func (r Row) Source() string {
+	return r.source
+}
+
+// Name returns the system variable name
+func (r Row) Name() string {
+	return r.system_variable_name
+}
+
+// CmdLine returns the command line column of the summary table
+func (r Row) CmdLine() string {
+	return r.cmd_line
+}
+
+// OptionFile returns the option file column of the summary table
+func (r Row) OptionFile() string {
+	return r.option_file
+}
+
+// SystemVar returns the system variable column of the summary table
+func (r Row) SystemVar() string {
+	return r.system_var
+}
+
+// Scope returns the variable scope: Global, Session or Both
+func (r Row) Scope() string {
+	return r.var_scope
+}
+
+// Dynamic returns the dynamic column of the summary table
+func (r Row) Dynamic() string {
+	return r.dynamic
+}
+
+// CommandLineFormat returns the command line format from the details table
+func (r Row) CommandLineFormat() string {
+	return r.command_line_format
+}
+
+// DefaultValue returns the default value from the details table
+func (r Row) DefaultValue() string {
+	return r.default_value
+}
+
+// DataType returns the type from the details table, e.g. integer or boolean
+func (r Row) DataType() string {
+	return r.data_type
+}
+
+// MinValue returns the minimum permitted value from the details table
+func (r Row) MinValue() string {
+	return r.min_value
+}
+
+// MaxValue returns the maximum permitted value from the details table
+func (r Row) MaxValue() string {
+	return r.max_value
+}
+
+// ValidValues returns the permitted values of an enumeration or set
+func (r Row) ValidValues() []string {
+	return r.valid_values
+}
 
 func (r Row) Print() {
 	fmt.Println("===")
@@ -48,6 +165,9 @@ func (r Row) Print() {
 	fmt.Println("command_line_format: ", r.command_line_format)
 	fmt.Println("default_value:       ", r.default_value)
 	fmt.Println("data_type:           ", r.data_type)
+	fmt.Println("min_value:           ", r.min_value)
+	fmt.Println("max_value:           ", r.max_value)
+	fmt.Println("valid_values:        ", strings.Join(r.valid_values, ","))
 	fmt.Println("   ")
 }
 
@@ -61,32 +181,44 @@ func (r Row) IsEmpty() bool {
 		len(r.dynamic)+
 		len(r.command_line_format)+
 		len(r.default_value)+
-		len(r.data_type) == 0
+		len(r.data_type)+
+		len(r.min_value)+
+		len(r.max_value)+
+		len(r.valid_values) == 0
 }
 
-func (r Row) InsertStatement(table_name string) {
-	column_names := []string{"system_variable_name", "cmd_line", "option_file", "system_var", "var_scope", "dynamic"}
-	column_values := []string{r.system_variable_name, r.cmd_line, r.option_file, r.system_var, r.var_scope, r.dynamic}
-	quoted_values := make([]string, 0, len(column_values))
-	for i := range column_values {
-		quoted_values = append(quoted_values, quote(column_values[i]))
-	}
+func (r Row) InsertStatement(w io.Writer, table_name string) {
+	r.compatInsertStatement(w, table_name, CreateMode)
+}
+
+// the columns of the original table layout which are inserted
+var compatColumnNames = []string{"system_variable_name", "cmd_line", "option_file", "system_var", "var_scope", "dynamic"}
 
-	s := "INSERT INTO " + table_name + " " +
-		"(" + strings.Join(column_names, ",") + ")" +
-		" VALUES " +
-		"(" + strings.Join(quoted_values, ",") + ")" +
-		";"
+// the INSERT statement of the original table layout for the SQL mode
+func (r Row) compatInsertStatement(w io.Writer, table_name string, mode SQLMode) {
+	prefix, clause := compatInsertSyntax(table_name, mode)
+	fmt.Fprintln(w, prefix+r.compatValues(MySQL)+clause+";")
+}
 
-	fmt.Println(s)
+// the start of the INSERT statement of the original table layout up to and
+// including VALUES, and the clause which follows the values
+func compatInsertSyntax(table_name string, mode SQLMode) (prefix, clause string) {
+	verb, clause := insertSyntax(MySQL, mode, compatColumnNames[0], compatColumnNames)
+	return verb + table_name + " (" + strings.Join(compatColumnNames, ",") + ") VALUES ", clause
 }
 
-// stupid quoting but good enough for me here.
-func quote(s string) string {
-	if s == "" {
-		return "NULL"
+// the values of the row in the original table layout, NULL if empty
+func (r Row) compatValues(d Dialect) string {
+	column_values := []string{r.system_variable_name, r.cmd_line, r.option_file, r.system_var, r.var_scope, r.dynamic}
+	quoted_values := make([]string, 0, len(column_values))
+	for i := range column_values {
+		if column_values[i] == "" {
+			quoted_values = append(quoted_values, "NULL")
+			continue
+		}
+		quoted_values = append(quoted_values, d.QuoteString(column_values[i]))
 	}
-	return "'" + s + "'"
+	return "(" + strings.Join(quoted_values, ",") + ")"
 }
 
 // return true if the two rows are the identical
@@ -99,7 +231,10 @@ func identical(r1, r2 Row) bool {
 		r1.dynamic == r2.dynamic &&
 		r1.command_line_format == r2.command_line_format &&
 		r1.default_value == r2.default_value &&
-		r1.data_type == r2.data_type
+		r1.data_type == r2.data_type &&
+		r1.min_value == r2.min_value &&
+		r1.max_value == r2.max_value &&
+		strings.Join(r1.valid_values, ",") == strings.Join(r2.valid_values, ",")
 }
 
 func showEmpty(s, comment string, answer bool) bool {
@@ -116,6 +251,9 @@ func empty(s string) bool {
 	if len(s) == 2 && s[0] == '\xc2' && s[1] == '\xa0' {
 		return showEmpty(s, "c2 a0 combo empty string", true)
 	}
+	if normalise(s) == "" {
+		return showEmpty(s, "white space only string", true)
+	}
 	return showEmpty(s, "non-empty string", false)
 }
 
@@ -147,7 +285,9 @@ func mergeable(r1, r2 Row) bool {
 		different(r1.dynamic, r2.dynamic) ||
 		different(r1.command_line_format, r2.command_line_format) ||
 		different(r1.default_value, r2.default_value) ||
-		different(r1.data_type, r2.data_type) {
+		different(r1.data_type, r2.data_type) ||
+		different(r1.min_value, r2.min_value) ||
+		different(r1.max_value, r2.max_value) {
 		return false
 	}
 	return true
@@ -171,4 +311,16 @@ func (r *Row) Merge(r2 Row) {
 	r.command_line_format = merge(r.command_line_format, r2.command_line_format)
 	r.default_value = merge(r.default_value, r2.default_value)
 	r.data_type = merge(r.data_type, r2.data_type)
+	r.min_value = merge(r.min_value, r2.min_value)
+	r.max_value = merge(r.max_value, r2.max_value)
+	if len(r.valid_values) == 0 {
+		r.valid_values = r2.valid_values
+	}
+	r.description = merge(r.description, r2.description)
+	if r2.source != "" {
+		r.source = r2.source // where the variable was first found
+	}
+	if r2.position.IsValid() {
+		r.position = r2.position
+	}
 }
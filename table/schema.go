@@ -0,0 +1,195 @@
+// generate the table definition from the row model
+package table
+
+import (
+	"fmt"
+	"strings"
+)
+
+// column describes a column of the generated table and how to get its value from a record
+type column struct {
+	name    string
+	kind    ColumnKind
+	size    int // of a varchar
+	notNull bool
+	value   func(rec Record) interface{} // a string, bool or nil for NULL
+}
+
+// the scopes shown in the summary table. Varies is used by a few variables
+// whose scope depends on the context.
+var scopes = []string{"Global", "Session", "Both", "Varies"}
+
+// the key of the table
+const keyColumn = "system_variable_name"
+
+// the columns holding the description of the variable and where it was
+// found, e.g. the member of an archive
+const (
+	descriptionColumn = "description"
+	sourceColumn      = "source"
+)
+
+// the columns of the typed table layout
+var columns = []column{
+	{keyColumn, VarcharColumn, 128, true, func(rec Record) interface{} { return rec.Name }},
+	{"cmd_line", BooleanColumn, 0, true, func(rec Record) interface{} { return rec.CmdLine }},
+	{"option_file", BooleanColumn, 0, true, func(rec Record) interface{} { return rec.OptionFile }},
+	{"system_var", BooleanColumn, 0, true, func(rec Record) interface{} { return rec.SystemVar }},
+	{"var_scope", EnumColumn, 0, false, func(rec Record) interface{} { return scope(rec.Scope) }},
+	{"dynamic", BooleanColumn, 0, false, func(rec Record) interface{} {
+		if rec.Dynamic == nil {
+			return nil
+		}
+		return *rec.Dynamic
+	}},
+	{"command_line_format", VarcharColumn, 255, false, func(rec Record) interface{} { return rec.CommandLineFormat }},
+	{"default_value", VarcharColumn, 1024, false, func(rec Record) interface{} { return rec.DefaultValue }},
+	{"data_type", VarcharColumn, 50, false, func(rec Record) interface{} { return rec.DataType }},
+	{"min_value", VarcharColumn, 64, false, func(rec Record) interface{} { return rec.MinValue }},
+	{"max_value", VarcharColumn, 64, false, func(rec Record) interface{} { return rec.MaxValue }},
+	{"valid_values", TextColumn, 0, false, func(rec Record) interface{} { return strings.Join(rec.ValidValues, ",") }},
+	{descriptionColumn, TextColumn, 0, false, func(rec Record) interface{} { return rec.Description }},
+	{sourceColumn, VarcharColumn, 255, false, func(rec Record) interface{} { return rec.Source }},
+}
+
+// KeyColumn returns the name of the primary key column
+func KeyColumn() string {
+	return keyColumn
+}
+
+// Columns returns the names of the columns of the typed layout
+func Columns() []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// ColumnDefinitions returns the definitions of the columns of the typed
+// layout as used in CREATE TABLE, without the primary key
+func ColumnDefinitions(d Dialect) []string {
+	definitions := make([]string, 0, len(columns))
+	for _, c := range columns {
+		definitions = append(definitions, columnDefinition(d, c))
+	}
+	return definitions
+}
+
+// the definition of the column as used in CREATE TABLE
+func columnDefinition(d Dialect, c column) string {
+	definition := d.QuoteIdentifier(c.name) + " " + d.ColumnType(c.name, c.kind, c.size, scopes)
+	switch {
+	case c.notNull && c.kind == BooleanColumn:
+		definition += " NOT NULL DEFAULT " + d.Boolean(false)
+	case c.notNull:
+		definition += " NOT NULL"
+	default:
+		definition += " DEFAULT NULL"
+	}
+	return definition
+}
+
+// ColumnValues returns the values of the record in the order of Columns
+// for use as query arguments. Empty strings are returned as nil for NULL.
+func (rec Record) ColumnValues() []interface{} {
+	values := make([]interface{}, 0, len(columns))
+	for _, c := range columns {
+		v := c.value(rec)
+		if v == "" {
+			v = nil
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// literal returns a column value as a SQL literal. Empty strings are NULL.
+func literal(d Dialect, v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		return d.Boolean(v)
+	case string:
+		if v != "" {
+			return d.QuoteString(v)
+		}
+	}
+	return "NULL"
+}
+
+// normalise a value from the documentation: surrounding white space,
+// including the non-breaking spaces used in empty cells, is removed.
+func normalise(s string) string {
+	return strings.TrimSpace(strings.Replace(s, "\u00a0", " ", -1))
+}
+
+// yesNo converts a Yes/No value from the documentation to a boolean.
+// known is false if the value is neither, e.g. empty.
+func yesNo(s string) (value bool, known bool) {
+	switch strings.ToLower(normalise(s)) {
+	case "yes":
+		return true, true
+	case "no":
+		return false, true
+	}
+	return false, false
+}
+
+// scope returns the scope if it's one of the enum values, otherwise nil
+func scope(s string) interface{} {
+	for _, v := range scopes {
+		if strings.EqualFold(s, v) {
+			return v
+		}
+	}
+	return nil
+}
+
+// the columns of the original table layout with every column as a varchar
+const compatColumns = ` (
+    system_variable_name varchar(255) NOT NULL,
+    cmd_line varchar(255) DEFAULT NULL,
+    option_file varchar(50) DEFAULT NULL,
+    system_var varchar(50) DEFAULT NULL,
+    var_scope varchar(50) DEFAULT NULL,
+    dynamic varchar(50) DEFAULT NULL,
+    data_type varchar(50) DEFAULT NULL,
+    PRIMARY KEY (system_variable_name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+`
+
+// createTableStatement returns the CREATE TABLE statement of the typed layout
+func createTableStatement(d Dialect, table_name string, mode SQLMode) string {
+	definitions := ColumnDefinitions(d)
+	definitions = append(definitions, "PRIMARY KEY ("+d.QuoteIdentifier(keyColumn)+")")
+
+	return fmt.Sprintf("%s (\n    %s\n)%s;\n",
+		createTableHeader(mode, d.QuoteIdentifier(table_name)), strings.Join(definitions, ",\n    "), d.TableOptions())
+}
+
+// typedInsertStatement returns the INSERT statement of the row for the typed layout
+func (r Row) typedInsertStatement(d Dialect, table_name string, mode SQLMode) string {
+	prefix, clause := typedInsertSyntax(d, table_name, mode)
+	return prefix + r.typedValues(d) + clause + ";"
+}
+
+// the start of the INSERT statement of the typed layout up to and including
+// VALUES, and the clause which follows the values
+func typedInsertSyntax(d Dialect, table_name string, mode SQLMode) (prefix, clause string) {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, d.QuoteIdentifier(c.name))
+	}
+	verb, clause := insertSyntax(d, mode, keyColumn, Columns())
+	return verb + d.QuoteIdentifier(table_name) + " (" + strings.Join(names, ",") + ") VALUES ", clause
+}
+
+// the values of the row in the typed layout
+func (r Row) typedValues(d Dialect) string {
+	values := make([]string, 0, len(columns))
+	rec := r.Record()
+	for _, c := range columns {
+		values = append(values, literal(d, c.value(rec)))
+	}
+	return "(" + strings.Join(values, ",") + ")"
+}
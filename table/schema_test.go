@@ -0,0 +1,206 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTypedInsertStatement(t *testing.T) {
+	var r Row
+	r.SetSystemVariableName("big-tables")
+	r.SetCmdLine("Yes")
+	r.SetOptionFile("Yes")
+	r.SetSystemVar(" ")
+	r.SetVarScope(" ")
+	r.SetDynamic("Yes")
+
+	want := "INSERT INTO `t` (`system_variable_name`,`cmd_line`,`option_file`,`system_var`,`var_scope`,`dynamic`,`command_line_format`,`default_value`,`data_type`,`min_value`,`max_value`,`valid_values`,`description`,`source`)" +
+		" VALUES ('big-tables',TRUE,TRUE,FALSE,NULL,TRUE,NULL,NULL,NULL,NULL,NULL,NULL,NULL,NULL);"
+	if got := r.typedInsertStatement(MySQL, "t", CreateMode); got != want {
+		t.Errorf("typedInsertStatement() = %s, want %s", got, want)
+	}
+
+	r.SetVarScope("Both")
+	r.SetDynamic("Varies")
+	r.SetDefaultValue(`C:\mysql's`)
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{"mysql", `'Both',NULL,NULL,'C:\\mysql\'s'`},
+		{"postgresql", `'Both',NULL,NULL,'C:\mysql''s'`},
+		{"sqlite", `('big-tables',1,1,0,'Both',NULL,NULL,'C:\mysql''s'`},
+	}
+	for _, test := range tests {
+		d, err := LookupDialect(test.dialect)
+		if err != nil {
+			t.Fatalf("LookupDialect(%s) failed: %v", test.dialect, err)
+		}
+		if got := r.typedInsertStatement(d, "t", CreateMode); !strings.Contains(got, test.want) {
+			t.Errorf("typedInsertStatement(%s) = %s, want %s", test.dialect, got, test.want)
+		}
+	}
+}
+
+func TestCreateTableStatement(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    []string
+	}{
+		{"mysql", []string{
+			"CREATE TABLE `sysvar57` (",
+			"    `cmd_line` BOOLEAN NOT NULL DEFAULT FALSE,",
+			"    `var_scope` ENUM('Global','Session','Both','Varies') DEFAULT NULL,",
+			"    PRIMARY KEY (`system_variable_name`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+		}},
+		{"postgresql", []string{
+			`CREATE TABLE "sysvar57" (`,
+			`    "cmd_line" boolean NOT NULL DEFAULT FALSE,`,
+			`    "var_scope" varchar(7) CHECK ("var_scope" IN ('Global','Session','Both','Varies')) DEFAULT NULL,`,
+			`    PRIMARY KEY ("system_variable_name")` + "\n);",
+		}},
+		{"sqlite", []string{
+			`    "cmd_line" INTEGER NOT NULL DEFAULT 0,`,
+			`    "valid_values" TEXT DEFAULT NULL,`,
+		}},
+	}
+	for _, test := range tests {
+		d, _ := LookupDialect(test.dialect)
+		got := createTableStatement(d, "sysvar57", CreateMode)
+		for _, want := range test.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("createTableStatement(%s) = %s, missing %q", test.dialect, got, want)
+			}
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	columns := []string{"system_variable_name", "dynamic"}
+	if got, want := MySQL.Upsert(keyColumn, columns), " ON DUPLICATE KEY UPDATE `dynamic`=VALUES(`dynamic`)"; got != want {
+		t.Errorf("mysql Upsert() = %s, want %s", got, want)
+	}
+	d, _ := LookupDialect("postgres")
+	if got, want := d.Upsert(keyColumn, columns), ` ON CONFLICT ("system_variable_name") DO UPDATE SET "dynamic"=excluded."dynamic"`; got != want {
+		t.Errorf("postgresql Upsert() = %s, want %s", got, want)
+	}
+}
+
+func TestUpsertKeyOnly(t *testing.T) {
+	columns := []string{"system_variable_name"}
+	if got, want := MySQL.Upsert(keyColumn, columns), " ON DUPLICATE KEY UPDATE `system_variable_name`=`system_variable_name`"; got != want {
+		t.Errorf("mysql Upsert() = %s, want %s", got, want)
+	}
+	if got, want := SQLite.Upsert(keyColumn, columns), ` ON CONFLICT ("system_variable_name") DO NOTHING`; got != want {
+		t.Errorf("sqlite Upsert() = %s, want %s", got, want)
+	}
+}
+
+func TestSQLModes(t *testing.T) {
+	var r Row
+	r.SetSystemVariableName("big_tables")
+	r.SetDynamic("Yes")
+
+	tests := []struct {
+		mode    string
+		dialect string
+		create  string
+		insert  string
+	}{
+		{"create", "mysql", "DROP TABLE IF EXISTS `t`;\nCREATE TABLE `t` (", "INSERT INTO `t` "},
+		{"create-if-not-exists", "mysql", "\nCREATE TABLE IF NOT EXISTS `t` (", "INSERT INTO `t` "},
+		{"replace", "mysql", "\nCREATE TABLE IF NOT EXISTS `t` (", "REPLACE INTO `t` "},
+		{"upsert", "mysql", "\nCREATE TABLE IF NOT EXISTS `t` (", " ON DUPLICATE KEY UPDATE `cmd_line`=VALUES(`cmd_line`),"},
+		{"insert-ignore", "mysql", "\nCREATE TABLE IF NOT EXISTS `t` (", "INSERT IGNORE INTO `t` "},
+		{"replace", "postgresql", `CREATE TABLE IF NOT EXISTS "t" (`, ` ON CONFLICT ("system_variable_name") DO UPDATE SET "cmd_line"=excluded."cmd_line",`},
+		{"insert-ignore", "postgresql", `CREATE TABLE IF NOT EXISTS "t" (`, ` ON CONFLICT ("system_variable_name") DO NOTHING;`},
+		{"insert-ignore", "sqlite", `CREATE TABLE IF NOT EXISTS "t" (`, `INSERT OR IGNORE INTO "t" `},
+	}
+	for _, test := range tests {
+		mode, err := LookupSQLMode(test.mode)
+		if err != nil {
+			t.Fatalf("LookupSQLMode(%s) failed: %v", test.mode, err)
+		}
+		d, _ := LookupDialect(test.dialect)
+		if got := createTableStatement(d, "t", mode); !strings.Contains(got, test.create) {
+			t.Errorf("createTableStatement(%s, %s) = %s, missing %q", test.dialect, test.mode, got, test.create)
+		}
+		if mode != CreateMode && strings.Contains(createTableStatement(d, "t", mode), "DROP TABLE") {
+			t.Errorf("createTableStatement(%s, %s) drops the table", test.dialect, test.mode)
+		}
+		if got := r.typedInsertStatement(d, "t", mode); !strings.Contains(got, test.insert) {
+			t.Errorf("typedInsertStatement(%s, %s) = %s, missing %q", test.dialect, test.mode, got, test.insert)
+		}
+	}
+	if _, err := LookupSQLMode("truncate"); err == nil {
+		t.Errorf("LookupSQLMode(truncate) succeeded")
+	}
+}
+
+// the statements between the start of the transaction and the commit
+func transaction(t *testing.T, sql string) []string {
+	begin := strings.Index(sql, "START TRANSACTION;\n")
+	commit := strings.LastIndex(sql, "\nCOMMIT;\n")
+	if begin < 0 || commit < begin {
+		t.Fatalf("the SQL isn't wrapped in a transaction:\n%s", sql)
+	}
+	return strings.Split(sql[begin:commit], "\n")[1:]
+}
+
+func TestTransaction(t *testing.T) {
+	tbl := NewTable("sysvar")
+	tbl.SetVersion("5.7")
+	var r Row
+	r.SetSystemVariableName("big_tables")
+	r.SetDynamic("Yes")
+	tbl.AppendRow(r)
+	tbl.SetTransaction(true)
+	tbl.SetDisableKeys(true)
+
+	for _, normalised := range []bool{false, true} {
+		tbl.SetNormalised(normalised)
+		var b bytes.Buffer
+		if err := tbl.Dump(&b); err != nil {
+			t.Fatalf("Dump() failed: %v", err)
+		}
+		// DDL commits implicitly so only the inserts may be in the transaction
+		inserts := 0
+		for _, line := range transaction(t, b.String()) {
+			switch {
+			case strings.HasPrefix(line, "INSERT"):
+				inserts++
+			case strings.HasPrefix(line, "DELETE"), strings.HasPrefix(line, "--"):
+			default:
+				t.Errorf("Dump() with normalised %v has %q in the transaction", normalised, line)
+			}
+		}
+		if inserts == 0 {
+			t.Errorf("Dump() with normalised %v has no inserts in the transaction:\n%s", normalised, b.String())
+		}
+	}
+}
+
+// each table escapes its strings for its own server
+func TestNoBackslashEscapes(t *testing.T) {
+	var r Row
+	r.SetSystemVariableName("basedir")
+	r.SetDefaultValue(`C:\mysql's`)
+
+	dump := func(nobs bool) string {
+		tbl := NewTable("sysvar")
+		tbl.AppendRow(r)
+		tbl.SetNoBackslashEscapes(nobs)
+		var b bytes.Buffer
+		if err := tbl.Dump(&b); err != nil {
+			t.Fatalf("Dump() failed: %v", err)
+		}
+		return b.String()
+	}
+	if got, want := dump(true), `'C:\mysql''s'`; !strings.Contains(got, want) {
+		t.Errorf("Dump() with NO_BACKSLASH_ESCAPES =\n%s\nmissing %s", got, want)
+	}
+	if got, want := dump(false), `'C:\\mysql\'s'`; !strings.Contains(got, want) {
+		t.Errorf("Dump() after another table used NO_BACKSLASH_ESCAPES =\n%s\nmissing %s", got, want)
+	}
+}
@@ -2,59 +2,205 @@ package table
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
 	"sort"
+	"strings"
 
 	"github.com/sjmudd/mysql-variables-parser/sysvar"
 )
 
 type Table struct {
-	name         string
-	rows         []Row
-	varNameToRow map[string]int // maps the variable name to the row it's stored in.
+	name               string
+	version            string // of the MySQL manual, e.g. 5.7
+	rows               []Row
+	varNameToRow       map[string]int    // maps the variable name to the row it's stored in.
+	compat             bool              // generate the original all varchar layout
+	dialect            Dialect           // the SQL dialect to generate
+	normalised         bool              // generate the normalised layout holding every version
+	mode               SQLMode           // how the SQL treats an existing table and rows
+	transaction        bool              // wrap the SQL in a transaction
+	maxStatementSize   int               // the largest multi-row INSERT, 0 for one row per INSERT
+	lockTables         bool              // lock the table while inserting
+	disableKeys        bool              // disable the keys while inserting
+	noBackslashEscapes bool              // for a server using the NO_BACKSLASH_ESCAPES sql_mode
+	provenance         *Provenance       // where the variables came from, nil if not known
+	conflicts          []sysvar.Conflict // values found twice which disagree
+	otherConflicts     int               // counted before the rows reached the table
 }
 
 // create a new table with the given name
 func NewTable(name string) *Table {
-	fmt.Println("-- New table:" + name)
 	t := new(Table)
 	t.name = name
 	t.varNameToRow = make(map[string]int)
+	t.dialect = MySQL
 	return t
 }
 
+// return the name of the table
+func (t Table) Name() string {
+	return t.name
+}
+
+// SetVersion sets the MySQL version the table describes
+func (t *Table) SetVersion(version string) {
+	t.version = version
+}
+
+// Version returns the MySQL version the table describes, empty if not known
+func (t Table) Version() string {
+	return t.version
+}
+
 // return the number of rows in the table
 func (t Table) Rows() int {
 	return len(t.rows)
 }
 
-// generate a create table statement, currently hard-coded
-func (t Table) CreateTableStatement() {
-	s := `-- Create table entry
-DROP TABLE IF EXISTS %s;
-CREATE TABLE %s (
-    system_variable_name varchar(255) NOT NULL,
-    cmd_line varchar(255) DEFAULT NULL,
-    option_file varchar(50) DEFAULT NULL,
-    system_var varchar(50) DEFAULT NULL,
-    var_scope varchar(50) DEFAULT NULL,
-    dynamic varchar(50) DEFAULT NULL,
-    data_type varchar(50) DEFAULT NULL,
-    PRIMARY KEY (system_variable_name)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-`
-	fmt.Printf(s, t.name, t.name)
+// CanonicalName returns the name of a variable as used by SET and
+// SHOW VARIABLES: the command line form uses dashes where the system
+// variable uses underscores.
+func CanonicalName(name string) string {
+	return strings.Replace(strings.ToLower(name), "-", "_", -1)
+}
+
+// Lookup returns the row for the given variable name. If there is no exact
+// match the canonical name is tried, so big-tables will find big_tables.
+func (t Table) Lookup(name string) (Row, bool) {
+	if i, ok := t.varNameToRow[name]; ok {
+		return t.rows[i], true
+	}
+	if i, ok := t.varNameToRow[CanonicalName(name)]; ok {
+		return t.rows[i], true
+	}
+	return Row{}, false
+}
+
+// MergeSysvarInfo adds the information collected from the "Options for"
+// detail tables to the summary rows with the same name.
+func (t *Table) MergeSysvarInfo(info sysvar.Info) {
+	t.conflicts = append(t.conflicts, info.Conflicts()...)
+	for i := range t.rows {
+		r := &t.rows[i]
+		name := r.system_variable_name
+		if v, found := lookupType(info.CmdLines(), name); found {
+			r.SetCommandLineFormat(v)
+		}
+		if v, found := lookupType(info.Defaults(), name); found {
+			r.SetDefaultValue(v)
+		}
+		if v, found := lookupType(info.ColumnTypes(), name); found {
+			r.SetDataType(v)
+		}
+		if v, found := lookupType(info.Minimums(), name); found {
+			r.SetMinValue(v)
+		}
+		if v, found := lookupType(info.Maximums(), name); found {
+			r.SetMaxValue(v)
+		}
+		if v, found := info.ValidValues()[name]; found {
+			r.SetValidValues(v)
+		} else if v, found := info.ValidValues()[CanonicalName(name)]; found {
+			r.SetValidValues(v)
+		}
+		if v, found := lookupType(info.Descriptions(), name); found {
+			r.SetDescription(v)
+		}
+	}
+}
+
+// look for the name in the map trying the canonical name if needed
+func lookupType(types sysvar.Types, name string) (string, bool) {
+	if v, found := types[name]; found {
+		return v, true
+	}
+	v, found := types[CanonicalName(name)]
+	return v, found
+}
+
+// SetDialect selects the SQL dialect to generate
+func (t *Table) SetDialect(d Dialect) {
+	t.dialect = d
+}
+
+// SetNoBackslashEscapes selects how MySQL strings are escaped. With the
+// NO_BACKSLASH_ESCAPES sql_mode a backslash is an ordinary character so
+// only the quote character can be escaped, by doubling it.
+func (t *Table) SetNoBackslashEscapes(b bool) {
+	t.noBackslashEscapes = b
+}
+
+// the dialect generated, escaping strings as the server expects
+func (t Table) sqlDialect() Dialect {
+	if t.noBackslashEscapes && t.dialect == MySQL {
+		return mysqlDialect{noBackslashEscapes: true}
+	}
+	return t.dialect
+}
+
+// SetCompatible selects the original table layout where every column is a
+// varchar holding the text from the documentation.
+func (t *Table) SetCompatible(compat bool) {
+	t.compat = compat
+}
+
+// SetNormalised selects the normalised layout where the variables of every
+// version are held in the same tables.
+func (t *Table) SetNormalised(normalised bool) {
+	t.normalised = normalised
+}
+
+// SetSQLMode selects how the SQL treats an existing table and rows
+func (t *Table) SetSQLMode(mode SQLMode) {
+	t.mode = mode
+}
+
+// SetTransaction selects whether the SQL is wrapped in a transaction
+func (t *Table) SetTransaction(transaction bool) {
+	t.transaction = transaction
+}
+
+// generate a create table statement from the row model
+func (t Table) CreateTableStatement(w io.Writer) {
+	if t.compat {
+		fmt.Fprint(w, createTableHeader(t.mode, t.name)+compatColumns)
+		return
+	}
+	fmt.Fprint(w, createTableStatement(t.sqlDialect(), t.name, t.mode))
 }
 
 // create the INSERT statements for the rows in the table
-func (t Table) InsertStatements() {
+func (t Table) InsertStatements(w io.Writer) {
 	if len(t.rows) > 0 {
-		fmt.Println("-- Insert rows")
+		fmt.Fprintln(w, "-- Insert rows")
+	}
+	var prefix, clause string
+	if t.compat {
+		prefix, clause = compatInsertSyntax(t.name, t.mode)
+	} else {
+		prefix, clause = typedInsertSyntax(t.sqlDialect(), t.name, t.mode)
 	}
+	values := make([]string, 0, len(t.rows))
 	for i := range t.rows {
-		if !t.rows[i].IsEmpty() {
-			t.rows[i].InsertStatement(t.name)
+		if t.rows[i].IsEmpty() {
+			continue
+		}
+		if t.compat {
+			values = append(values, t.rows[i].compatValues(t.sqlDialect()))
+		} else {
+			values = append(values, t.rows[i].typedValues(t.sqlDialect()))
 		}
 	}
+
+	t.lockTable(w)
+	t.begin(w)
+	for _, s := range extendedInserts(prefix, clause, values, t.maxStatementSize) {
+		fmt.Fprintln(w, s)
+	}
+	t.commit(w)
+	t.unlockTable(w)
 }
 
 // AppendRow Appends a row to the table if the variable name has not been seen.
@@ -74,9 +220,19 @@ func (t *Table) AppendRow(row Row) {
 				t.rows[i] = row
 				// fmt.Println("Gives:    ", row)
 			} else {
-				fmt.Println("NOT THE SAME AND NOT MERGEABLE")
-				fmt.Println("previous:", t.rows[i])
-				fmt.Println("latest:  ", row)
+				c := sysvar.Conflict{
+					Name:             row.system_variable_name,
+					Field:            "row",
+					PreviousPosition: t.rows[i].position,
+					Position:         row.position,
+				}
+				t.conflicts = append(t.conflicts, c)
+				slog.Warn("conflicting rows",
+					"variable", c.Name,
+					"previous", fmt.Sprint(t.rows[i]),
+					"previous_position", c.PreviousPosition,
+					"latest", fmt.Sprint(row),
+					"position", c.Position)
 			}
 		} else {
 			// fmt.Println("Duplicate row:", row.system_variable_name, "matches: ignoring")
@@ -87,6 +243,20 @@ func (t *Table) AppendRow(row Row) {
 	}
 }
 
+// AppendTable appends the rows of another table
+func (t *Table) AppendTable(other *Table) {
+	t.conflicts = append(t.conflicts, other.conflicts...)
+	t.otherConflicts += other.otherConflicts
+	for _, row := range other.rows {
+		t.AppendRow(row)
+	}
+}
+
+// Conflicts returns the values found twice which disagree
+func (t Table) Conflicts() []sysvar.Conflict {
+	return t.conflicts
+}
+
 // Print the contents of the rows in the table.
 func (t Table) Print() {
 	for i := range t.rows {
@@ -95,9 +265,50 @@ func (t Table) Print() {
 }
 
 // Generate the equivalent of a mysqldump <db> <table>.
-func (t Table) MysqlDump() {
-	t.CreateTableStatement()
-	t.InsertStatements()
+func (t Table) MysqlDump() error {
+	return t.Dump(os.Stdout)
+}
+
+// Dump writes the equivalent of a mysqldump <db> <table> to w, or the
+// normalised layout if selected.
+func (t Table) Dump(w io.Writer) error {
+	if t.normalised {
+		return t.NormalisedDump(w)
+	}
+	// the original layout is written as it always was
+	if !t.compat {
+		t.provenanceComment(w)
+	}
+	fmt.Fprintln(w, "-- New table:"+t.name)
+	t.CreateTableStatement(w)
+	t.InsertStatements(w)
+	return nil
+}
+
+// start the transaction if the SQL is wrapped in one. MySQL commits
+// implicitly before DDL so the transaction starts after it.
+func (t Table) begin(w io.Writer) {
+	if t.transaction {
+		fmt.Fprintln(w, t.dialect.BeginTransaction()+";")
+	}
+}
+
+// commit the transaction if the SQL is wrapped in one
+func (t Table) commit(w io.Writer) {
+	if t.transaction {
+		fmt.Fprintln(w, "COMMIT;")
+	}
+}
+
+// Records returns the non-empty rows of the table as records
+func (t Table) Records() []Record {
+	records := make([]Record, 0, len(t.rows))
+	for i := range t.rows {
+		if !t.rows[i].IsEmpty() {
+			records = append(records, t.rows[i].Record())
+		}
+	}
+	return records
 }
 
 type Keys []string
@@ -112,7 +323,7 @@ func (k Keys) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
 func (t Table) MysqlDumpFromSysvars(types, cmdlines, scopes, defaults, dynamics sysvar.Types) {
 	m := make(sysvar.Types)
 
-	t.CreateTableStatement()
+	t.CreateTableStatement(os.Stdout)
 
 	// combine all the variable names we have together
 	for k, v := range types {
@@ -154,7 +365,7 @@ func (t Table) MysqlDumpFromSysvars(types, cmdlines, scopes, defaults, dynamics
 			r.SetDynamic(v)
 		}
 		if !r.IsEmpty() {
-			r.InsertStatement(t.name)
+			r.InsertStatement(os.Stdout, t.name)
 		}
 	}
 
@@ -1,9 +1,40 @@
 package util
 
-// stupid SQL quoting but good enough for me.
+import (
+	"strings"
+)
+
+// the characters escaped by mysql_real_escape_string()
+var backslashEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"'", "\\'",
+	"\"", "\\\"",
+	"\x00", "\\0",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\x1a", "\\Z",
+)
+
+var quoteEscaper = strings.NewReplacer("'", "''")
+
+// QuoteString returns s as a single quoted SQL string literal escaped as
+// mysql_real_escape_string() does
+func QuoteString(s string) string {
+	return "'" + backslashEscaper.Replace(s) + "'"
+}
+
+// QuoteStandardString returns s as a single quoted string literal using
+// only the standard SQL escape of doubling the quote character. This is
+// the only escape with the NO_BACKSLASH_ESCAPES sql_mode, where a
+// backslash is an ordinary character.
+func QuoteStandardString(s string) string {
+	return "'" + quoteEscaper.Replace(s) + "'"
+}
+
+// Quote returns s as a SQL string literal, or NULL if s is empty
 func Quote(s string) string {
 	if s == "" {
 		return "NULL"
 	}
-	return "'" + s + "'"
+	return QuoteString(s)
 }
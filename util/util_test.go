@@ -0,0 +1,46 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "NULL"},
+		{"OFF", "'OFF'"},
+		// ft_boolean_syntax
+		{`+ -><()~*:""&|`, `'+ -><()~*:\"\"&|'`},
+		// basedir on Windows
+		{`C:\Program Files\MySQL\MySQL Server 5.7\`, `'C:\\Program Files\\MySQL\\MySQL Server 5.7\\'`},
+		// a quoted value as shown for some defaults
+		{`'NO_ENGINE_SUBSTITUTION'`, `'\'NO_ENGINE_SUBSTITUTION\''`},
+		{"a\x00b\nc\rd\x1ae", `'a\0b\nc\rd\Ze'`},
+	}
+	for _, test := range tests {
+		if got := Quote(test.in); got != test.want {
+			t.Errorf("Quote(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func TestQuoteStandardString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`+ -><()~*:""&|`, `'+ -><()~*:""&|'`},
+		{`C:\Program Files\MySQL\MySQL Server 5.7\`, `'C:\Program Files\MySQL\MySQL Server 5.7\'`},
+		{`'NO_ENGINE_SUBSTITUTION'`, `'''NO_ENGINE_SUBSTITUTION'''`},
+	}
+	for _, test := range tests {
+		if got := QuoteStandardString(test.in); got != test.want {
+			t.Errorf("QuoteStandardString(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+	if got := QuoteStandardString(""); got != "''" {
+		t.Errorf("QuoteStandardString(\"\") = %s, want ''", got)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/planner"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/validate"
+)
+
+// check SET statements or a mysqld-auto.cnf file against the variables
+func validateCommand(args []string) int {
+	flags := newFlagSet("validate", "", "Check the SET statements of a SQL file, or the variables of a mysqld-auto.cnf file.")
+	input := flags.String("input", defaultInput, "The page documenting the variables, - for stdin")
+	set := flags.String("set", "", "SQL file whose SET statements are checked")
+	persisted := flags.String("persisted", "", "mysqld-auto.cnf file whose variables are checked")
+	render := flags.Bool("render-cnf", false, "With --persisted print the variables as my.cnf settings")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	switch {
+	case flags.NArg() > 0:
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	case (*set == "") == (*persisted == ""):
+		return usageError(flags, "one of --set or --persisted is needed")
+	case *render && *persisted == "":
+		return usageError(flags, "--render-cnf can only be used with --persisted")
+	}
+	return check(*input, page, *set, *persisted, *render)
+}
+
+// check the SET statements or persisted variables printing any problems.
+// Returns the exit code.
+func check(input string, page pageFlags, set, persisted string, render bool) int {
+	t, err := page.parsePage(input)
+	if err != nil {
+		return failure("Failed to read %s: %v", input, err)
+	}
+	if set != "" {
+		return validateSet(t, set)
+	}
+	return validatePersisted(t, persisted, render)
+}
+
+// check the SET statements in filename printing any problems
+func validateSet(t *table.Table, filename string) int {
+	fi, err := os.Open(filename)
+	if err != nil {
+		return failure("Failed to open %s: %v", filename, err)
+	}
+	defer fi.Close()
+
+	problems, err := validate.SetStatements(t, fi)
+	if err != nil {
+		return failure("Failed to read %s: %v", filename, err)
+	}
+	for i := range problems {
+		fmt.Printf("%s:%s\n", filename, problems[i])
+	}
+	if len(problems) > 0 {
+		return exitProblems
+	}
+	return exitOK
+}
+
+// check the variables of the mysqld-auto.cnf file printing any problems.
+// If render is set the equivalent option file settings are printed and the
+// problems go to stderr.
+func validatePersisted(t *table.Table, filename string, render bool) int {
+	fi, err := os.Open(filename)
+	if err != nil {
+		return failure("Failed to open %s: %v", filename, err)
+	}
+	defer fi.Close()
+
+	variables, err := config.ParsePersisted(fi)
+	if err != nil {
+		return failure("Failed to read %s: %v", filename, err)
+	}
+	problems := validate.CheckPersisted(t, variables)
+	var w io.Writer = os.Stdout
+	if render {
+		w = os.Stderr
+	}
+	for i := range problems {
+		fmt.Fprintf(w, "%s: %s\n", filename, problems[i])
+	}
+	if render {
+		options := make([]config.Option, 0, len(variables))
+		for _, v := range variables {
+			options = append(options, config.Option{Section: "mysqld", Name: v.Name, Value: v.Value})
+		}
+		if err := config.WriteOptionFile(os.Stdout, options); err != nil {
+			return failure("Failed to write the option file: %v", err)
+		}
+	}
+	if len(problems) > 0 {
+		return exitProblems
+	}
+	return exitOK
+}
+
+// plan the changes needed to apply an option file to a server
+func planCommand(args []string) int {
+	flags := newFlagSet("plan", "<my.cnf>", "Plan the changes needed to apply the settings of an option file to a server.")
+	input := flags.String("input", defaultInput, "The page documenting the variables, - for stdin")
+	current := flags.String("current", "", "File containing the SHOW VARIABLES output of the server to plan changes for")
+	page := addPageFlags(flags)
+	if rc, ok := parseFlags(flags, args); !ok {
+		return rc
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags, "one option file is needed")
+	}
+	return plan(*input, page, flags.Arg(0), *current)
+}
+
+// print the plan to change the server described by current to the settings in optionFile
+func plan(input string, page pageFlags, optionFile, current string) int {
+	t, err := page.parsePage(input)
+	if err != nil {
+		return failure("Failed to read %s: %v", input, err)
+	}
+	fi, err := os.Open(optionFile)
+	if err != nil {
+		return failure("Failed to open %s: %v", optionFile, err)
+	}
+	defer fi.Close()
+	options, err := config.ParseOptionFile(fi)
+	if err != nil {
+		return failure("Failed to read %s: %v", optionFile, err)
+	}
+
+	variables := make(map[string]string)
+	if current != "" {
+		fc, err := os.Open(current)
+		if err != nil {
+			return failure("Failed to open %s: %v", current, err)
+		}
+		defer fc.Close()
+		if variables, err = config.ParseVariables(fc); err != nil {
+			return failure("Failed to read %s: %v", current, err)
+		}
+	}
+
+	planner.New(t, variables, options).Print()
+	return exitOK
+}
@@ -0,0 +1,82 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/value"
+)
+
+// the prefixes which turn a boolean option on or off
+var booleanPrefixes = []string{"skip-", "skip_", "enable-", "enable_", "disable-", "disable_"}
+
+// lookup the variable an option sets, allowing for the skip-, enable- and
+// disable- prefixes of boolean options. prefixed is true if one was used.
+func lookupOption(t *table.Table, name string) (row table.Row, prefixed bool, found bool) {
+	if row, found := t.Lookup(name); found {
+		return row, false, true
+	}
+	for _, prefix := range booleanPrefixes {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			if row, found := t.Lookup(name[len(prefix):]); found {
+				return row, true, true
+			}
+		}
+	}
+	return table.Row{}, false, false
+}
+
+// CheckOptions checks the settings of the server sections of an option file
+// against the table of variables. Options of other programs are ignored.
+// Settings which are unknown, can't be set in an option file, have an
+// invalid value or are repeated are reported. Other server options, such
+// as those only documented for the command line, are reported as unknown.
+func CheckOptions(t *table.Table, options []config.Option) []Problem {
+	var problems []Problem
+	seen := make(map[string]config.Option)
+
+	for _, o := range options {
+		if !o.IsServer() {
+			continue
+		}
+		report := func(format string, args ...interface{}) {
+			problems = append(problems, Problem{
+				Line:     o.Line,
+				Column:   1,
+				Variable: o.Name,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		row, prefixed, found := lookupOption(t, o.Name)
+		if !found {
+			report("unknown system variable '%s'", o.Name)
+			continue
+		}
+		name := table.CanonicalName(row.Name())
+		if previous, found := seen[o.Section+"."+name]; found {
+			report("'%s' is already set on line %d: the last setting is used", o.Name, previous.Line)
+		}
+		seen[o.Section+"."+name] = o
+
+		if !row.Record().OptionFile {
+			report("'%s' can not be set in an option file", o.Name)
+			continue
+		}
+		if prefixed {
+			if value.TypeOf(row) != value.Boolean {
+				report("'%s' is not a boolean so can't be set with a prefix", o.Name)
+			}
+			continue
+		}
+		if o.Value == "" && value.TypeOf(row) == value.Boolean {
+			continue // the option on its own turns it on
+		}
+		if _, err := value.Parse(row, o.Value); err != nil {
+			report("%s", err)
+		}
+	}
+	return problems
+}
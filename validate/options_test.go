@@ -0,0 +1,52 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+)
+
+func TestCheckOptions(t *testing.T) {
+	cnf := `[client]
+port=3306
+no_such_client_option=1
+
+[mysqld]
+max_connections=500
+max_connections=100001
+binlog-format=ROW
+skip-autocommit
+skip-binlog-format
+sql_log_bin=1
+no_such_variable=1
+`
+	tbl := testTable()
+	for _, name := range []string{"autocommit", "binlog_format", "max_connections"} {
+		row, _ := tbl.Lookup(name)
+		row.SetOptionFile("Yes")
+		tbl.AppendRow(row)
+	}
+
+	options, err := config.ParseOptionFile(strings.NewReader(cnf))
+	if err != nil {
+		t.Fatalf("ParseOptionFile() failed: %v", err)
+	}
+	want := []string{
+		"7:1: 'max_connections' is already set on line 6: the last setting is used",
+		"7:1: invalid value '100001' for max_connections: greater than the maximum value 100000",
+		"10:1: 'skip-binlog-format' is already set on line 8: the last setting is used",
+		"10:1: 'skip-binlog-format' is not a boolean so can't be set with a prefix",
+		"11:1: 'sql_log_bin' can not be set in an option file",
+		"12:1: unknown system variable 'no_such_variable'",
+	}
+	problems := CheckOptions(tbl, options)
+	if len(problems) != len(want) {
+		t.Fatalf("CheckOptions() = %v, want %d problems", problems, len(want))
+	}
+	for i := range want {
+		if problems[i].String() != want[i] {
+			t.Errorf("problem %d = %q, want %q", i, problems[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/value"
+)
+
+// CheckPersisted checks the variables read from mysqld-auto.cnf against the
+// table of variables: they must be known system variables in the section
+// matching whether they are dynamic, with a valid value.
+func CheckPersisted(t *table.Table, variables []config.Persisted) []Problem {
+	var problems []Problem
+
+	for _, p := range variables {
+		report := func(format string, args ...interface{}) {
+			problems = append(problems, Problem{
+				Variable: p.Name,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		row, found := t.Lookup(p.Name)
+		if !found {
+			report("unknown system variable '%s'", p.Name)
+			continue
+		}
+		rec := row.Record()
+		if !rec.SystemVar {
+			report("'%s' is not a system variable and can not be persisted", p.Name)
+			continue
+		}
+		if rec.Dynamic != nil {
+			switch {
+			case *rec.Dynamic && p.Static:
+				report("variable '%s' is dynamic but is in %s: it should be in %s", p.Name, p.Section, config.PersistedDynamic)
+			case !*rec.Dynamic && !p.Static:
+				report("variable '%s' is not dynamic but is in %s: it should be in %s", p.Name, p.Section, config.PersistedStatic)
+			}
+		}
+		if _, err := value.Parse(row, p.Value); err != nil {
+			report("%s", err)
+		}
+	}
+	return problems
+}
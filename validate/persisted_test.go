@@ -0,0 +1,44 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/config"
+)
+
+func TestCheckPersisted(t *testing.T) {
+	auto := `{"Version": 1, "mysql_server": {
+  "max_connections": {"Value": "500", "Metadata": {"Timestamp": 1570000000000000, "User": "root", "Host": "localhost"}},
+  "binlog_format": {"Value": "BLOCK", "Metadata": {"Timestamp": 1570000000000000, "User": "root", "Host": "localhost"}},
+  "back_log": {"Value": "100", "Metadata": {"Timestamp": 1570000000000000, "User": "root", "Host": "localhost"}},
+  "no_such_variable": {"Value": "1", "Metadata": {"Timestamp": 1570000000000000, "User": "root", "Host": "localhost"}},
+  "mysql_server_static_options": {
+    "autocommit": {"Value": "ON", "Metadata": {"Timestamp": 1570000000000000, "User": "root", "Host": "localhost"}},
+    "back_log": {"Value": "80", "Metadata": {"Timestamp": 1570000000000000, "User": "root", "Host": "localhost"}}
+  }
+}}`
+	variables, err := config.ParsePersisted(strings.NewReader(auto))
+	if err != nil {
+		t.Fatalf("ParsePersisted() failed: %v", err)
+	}
+	if len(variables) != 6 || variables[len(variables)-1].Section != config.PersistedStatic || !variables[len(variables)-1].Static {
+		t.Fatalf("ParsePersisted() = %+v, want 6 variables ending with the static ones", variables)
+	}
+
+	want := []string{
+		"variable 'back_log' is not dynamic but is in mysql_server: it should be in mysql_server_static_options",
+		"invalid value 'BLOCK' for binlog_format: expected one of ROW, STATEMENT, MIXED",
+		"unknown system variable 'no_such_variable'",
+		"variable 'autocommit' is dynamic but is in mysql_server_static_options: it should be in mysql_server",
+	}
+	problems := CheckPersisted(testTable(), variables)
+	if len(problems) != len(want) {
+		t.Fatalf("CheckPersisted() = %v, want %d problems", problems, len(want))
+	}
+	for i := range want {
+		if problems[i].String() != want[i] {
+			t.Errorf("problem %d = %q, want %q", i, problems[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package validate
+
+import (
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	identToken    tokenKind = iota // keyword or identifier
+	stringToken                    // quoted string, text is the unquoted value
+	numberToken                    // numeric literal
+	variableToken                  // @user_var or @@system_var
+	punctToken                     // anything else: = := , ; ( ) etc
+)
+
+// token is a lexical token of the SQL input with its position
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+}
+
+// is returns true if the token is the given keyword or punctuation, ignoring case
+func (t token) is(s string) bool {
+	return (t.kind == identToken || t.kind == punctToken) && strings.EqualFold(t.text, s)
+}
+
+// scanner splits SQL text into tokens keeping track of line and column
+type scanner struct {
+	input  string
+	pos    int
+	line   int
+	column int
+}
+
+func newScanner(input string) *scanner {
+	return &scanner{input: input, line: 1, column: 1}
+}
+
+func (s *scanner) peek(offset int) byte {
+	if s.pos+offset < len(s.input) {
+		return s.input[s.pos+offset]
+	}
+	return 0
+}
+
+func (s *scanner) advance() byte {
+	b := s.input[s.pos]
+	s.pos++
+	if b == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column++
+	}
+	return b
+}
+
+func isIdentChar(b byte) bool {
+	return b == '_' || b == '$' ||
+		(b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') ||
+		b >= 0x80
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// skip white space and comments
+func (s *scanner) skip() {
+	for s.pos < len(s.input) {
+		b := s.peek(0)
+		switch {
+		case b == ' ' || b == '\t' || b == '\r' || b == '\n':
+			s.advance()
+		case b == '#' || (b == '-' && s.peek(1) == '-' && (s.peek(2) == ' ' || s.peek(2) == '\t' || s.peek(2) == '\n' || s.peek(2) == 0)):
+			for s.pos < len(s.input) && s.peek(0) != '\n' {
+				s.advance()
+			}
+		case b == '/' && s.peek(1) == '*':
+			s.advance()
+			s.advance()
+			for s.pos < len(s.input) && !(s.peek(0) == '*' && s.peek(1) == '/') {
+				s.advance()
+			}
+			if s.pos < len(s.input) {
+				s.advance()
+				s.advance()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// read a quoted string or identifier handling doubled quotes and backslash escapes
+func (s *scanner) quoted(quote byte) string {
+	var b strings.Builder
+	s.advance()
+	for s.pos < len(s.input) {
+		c := s.advance()
+		switch {
+		case c == quote && s.peek(0) == quote:
+			s.advance()
+			b.WriteByte(c)
+		case c == quote:
+			return b.String()
+		case c == '\\' && quote != '`' && s.pos < len(s.input):
+			b.WriteByte(unescape(s.advance()))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// the MySQL backslash escape sequences
+func unescape(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 26
+	}
+	return c
+}
+
+// next returns the next token and false at the end of the input
+func (s *scanner) next() (token, bool) {
+	s.skip()
+	if s.pos >= len(s.input) {
+		return token{}, false
+	}
+	t := token{line: s.line, column: s.column}
+	start := s.pos
+	b := s.peek(0)
+	switch {
+	case b == '\'' || b == '"':
+		t.kind = stringToken
+		t.text = s.quoted(b)
+	case b == '`':
+		t.kind = identToken
+		t.text = s.quoted(b)
+	case b == '@':
+		t.kind = variableToken
+		s.advance()
+		if s.peek(0) == '@' {
+			s.advance()
+		}
+		for s.pos < len(s.input) && (isIdentChar(s.peek(0)) || s.peek(0) == '.') {
+			s.advance()
+		}
+		t.text = s.input[start:s.pos]
+	case isDigit(b) || (b == '.' && isDigit(s.peek(1))):
+		t.kind = numberToken
+		for s.pos < len(s.input) && (isIdentChar(s.peek(0)) || s.peek(0) == '.') {
+			s.advance()
+		}
+		t.text = s.input[start:s.pos]
+	case isIdentChar(b):
+		t.kind = identToken
+		for s.pos < len(s.input) && isIdentChar(s.peek(0)) {
+			s.advance()
+		}
+		t.text = s.input[start:s.pos]
+	case b == ':' && s.peek(1) == '=':
+		t.kind = punctToken
+		s.advance()
+		s.advance()
+		t.text = ":="
+	default:
+		t.kind = punctToken
+		s.advance()
+		t.text = s.input[start:s.pos]
+	}
+	return t, true
+}
+
+// statements splits the input into statements, each a list of tokens
+func statements(input string) [][]token {
+	var (
+		result  [][]token
+		current []token
+	)
+	s := newScanner(input)
+	for {
+		t, ok := s.next()
+		if !ok {
+			break
+		}
+		if t.is(";") {
+			if len(current) > 0 {
+				result = append(result, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, t)
+	}
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
@@ -0,0 +1,241 @@
+// Package validate checks SQL statements against the system variable
+// definitions collected by the parser.
+package validate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+	"github.com/sjmudd/mysql-variables-parser/value"
+)
+
+// The scopes which may be given to SET
+const (
+	Global      = "GLOBAL"
+	Session     = "SESSION"
+	Persist     = "PERSIST"
+	PersistOnly = "PERSIST_ONLY"
+)
+
+// Problem describes something wrong with a SET statement
+type Problem struct {
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Variable string `json:"variable,omitempty"`
+	Message  string `json:"message"`
+}
+
+// String returns the problem in the usual line:column: message form, or
+// just the message if there is no position
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// Assignment is a single variable assignment of a SET statement
+type Assignment struct {
+	Line     int
+	Column   int
+	Scope    string // one of the scope constants
+	Implicit bool   // true if no scope was given
+	Variable string
+	Value    string // the literal value, empty if not a literal
+	Literal  bool   // true if the value is a single literal
+	Quoted   bool   // true if the value was a quoted string
+}
+
+// statements which start with SET but don't set system variables
+var ignoredSets = map[string]bool{
+	"NAMES":       true,
+	"CHARACTER":   true,
+	"CHARSET":     true,
+	"PASSWORD":    true,
+	"TRANSACTION": true,
+	"DEFAULT":     true,
+	"ROLE":        true,
+	"RESOURCE":    true,
+}
+
+// scope keywords and the scope they represent
+var scopeKeywords = map[string]string{
+	"GLOBAL":       Global,
+	"SESSION":      Session,
+	"LOCAL":        Session,
+	"PERSIST":      Persist,
+	"PERSIST_ONLY": PersistOnly,
+}
+
+// split the tokens on commas which are not inside parentheses
+func splitList(tokens []token) [][]token {
+	var (
+		result [][]token
+		start  int
+		depth  int
+	)
+	for i, t := range tokens {
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case t.is(",") && depth == 0:
+			result = append(result, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, tokens[start:])
+}
+
+// parseSystemVariable splits @@global.name into its scope and name
+func parseSystemVariable(text string) (string, string) {
+	name := strings.TrimPrefix(text, "@@")
+	if i := strings.Index(name, "."); i >= 0 {
+		if scope, found := scopeKeywords[strings.ToUpper(name[:i])]; found {
+			return scope, name[i+1:]
+		}
+	}
+	return "", name
+}
+
+// parseSet returns the system variable assignments of a SET statement.
+// The scope keyword carries over to following assignments without one,
+// as it does in the server.
+func parseSet(tokens []token) []Assignment {
+	var result []Assignment
+
+	if len(tokens) < 2 || !tokens[0].is("SET") {
+		return nil
+	}
+	if tokens[1].kind == identToken && ignoredSets[strings.ToUpper(tokens[1].text)] {
+		return nil
+	}
+	scope := ""
+	for _, item := range splitList(tokens[1:]) {
+		if len(item) == 0 {
+			continue
+		}
+		a := Assignment{Line: item[0].line, Column: item[0].column}
+		i := 0
+		if item[0].kind == identToken {
+			if s, found := scopeKeywords[strings.ToUpper(item[0].text)]; found {
+				scope = s
+				i++
+			}
+		}
+		if i >= len(item) {
+			continue
+		}
+		if item[i].kind == identToken && ignoredSets[strings.ToUpper(item[i].text)] {
+			return nil // SET SESSION TRANSACTION ...
+		}
+		switch item[i].kind {
+		case variableToken:
+			if !strings.HasPrefix(item[i].text, "@@") {
+				continue // user variable
+			}
+			s, name := parseSystemVariable(item[i].text)
+			a.Variable = name
+			a.Scope = s
+		case identToken:
+			a.Variable = item[i].text
+			a.Scope = scope
+		default:
+			continue
+		}
+		if a.Scope == "" {
+			a.Scope = Session
+			a.Implicit = true
+		}
+		i++
+		if i >= len(item) || !(item[i].is("=") || item[i].is(":=")) {
+			continue
+		}
+		value := item[i+1:]
+		switch {
+		case len(value) == 1 && value[0].is("DEFAULT"):
+		case len(value) == 1 && value[0].kind != punctToken && value[0].kind != variableToken:
+			a.Value = value[0].text
+			a.Literal = true
+			a.Quoted = value[0].kind == stringToken
+		case len(value) == 2 && value[0].is("-") && value[1].kind == numberToken:
+			a.Value = "-" + value[1].text
+			a.Literal = true
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// Assignments returns the system variable assignments of all the SET
+// statements in the SQL text.
+func Assignments(sql string) []Assignment {
+	var result []Assignment
+	for _, statement := range statements(sql) {
+		result = append(result, parseSet(statement)...)
+	}
+	return result
+}
+
+// CheckAssignment checks a single assignment against the table of variables
+func CheckAssignment(t *table.Table, a Assignment) []Problem {
+	var problems []Problem
+
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, Problem{
+			Line:     a.Line,
+			Column:   a.Column,
+			Variable: a.Variable,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	row, found := t.Lookup(a.Variable)
+	if !found {
+		report("unknown system variable '%s'", a.Variable)
+		return problems
+	}
+	if a.Scope != PersistOnly && row.Dynamic() == "No" {
+		report("variable '%s' is not dynamic and can not be set at runtime", a.Variable)
+	}
+	switch row.Scope() {
+	case "Global":
+		if a.Scope == Session {
+			if a.Implicit {
+				report("variable '%s' is a GLOBAL variable and should be set with SET GLOBAL", a.Variable)
+			} else {
+				report("variable '%s' is a GLOBAL variable and can not be set with SET SESSION", a.Variable)
+			}
+		}
+	case "Session":
+		if a.Scope != Session {
+			report("variable '%s' is a SESSION variable and can not be set with SET %s", a.Variable, a.Scope)
+		}
+	}
+	if a.Literal {
+		isNumber := value.TypeOf(row) == value.Integer || value.TypeOf(row) == value.Numeric
+		if _, err := value.ParseSQL(row, a.Value); err != nil {
+			report("%s", err)
+		} else if isNumber && a.Quoted {
+			report("invalid value '%s' for %s: expected a number not a string", a.Value, a.Variable)
+		}
+	}
+	return problems
+}
+
+// SetStatements checks the SET statements read from r returning any problems found
+func SetStatements(t *table.Table, r io.Reader) ([]Problem, error) {
+	var problems []Problem
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range Assignments(string(b)) {
+		problems = append(problems, CheckAssignment(t, a)...)
+	}
+	return problems, nil
+}
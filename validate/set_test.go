@@ -0,0 +1,91 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func newRow(name, scope, dynamic, dataType, min, max string, values ...string) table.Row {
+	var r table.Row
+	r.SetSystemVariableName(name)
+	r.SetSystemVar("Yes")
+	r.SetVarScope(scope)
+	r.SetDynamic(dynamic)
+	r.SetDataType(dataType)
+	r.SetMinValue(min)
+	r.SetMaxValue(max)
+	r.SetValidValues(values)
+	return r
+}
+
+func testTable() *table.Table {
+	t := table.NewTable("test")
+	t.AppendRow(newRow("autocommit", "Both", "Yes", "boolean", "", ""))
+	t.AppendRow(newRow("back_log", "Global", "No", "integer", "1", "65535"))
+	t.AppendRow(newRow("binlog_format", "Both", "Yes", "enumeration", "", "", "ROW", "STATEMENT", "MIXED"))
+	t.AppendRow(newRow("max_connections", "Global", "Yes", "integer", "1", "100000"))
+	t.AppendRow(newRow("sql_log_bin", "Session", "Yes", "boolean", "", ""))
+	t.AppendRow(newRow("sql_mode", "Both", "Yes", "set", "", "", "ANSI_QUOTES", "STRICT_TRANS_TABLES", "NO_ZERO_DATE"))
+	return t
+}
+
+func TestAssignments(t *testing.T) {
+	sql := `-- a comment; with a semicolon
+SET @x = 1, GLOBAL max_connections = 500, sort_buffer_size := 1024*1024;
+SET NAMES utf8mb4;
+set @@persist_only.back_log = 'x;y';
+SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED;
+SET @@sql_mode = DEFAULT /* reset */;
+`
+	got := Assignments(sql)
+	want := []Assignment{
+		{Line: 2, Column: 13, Scope: Global, Variable: "max_connections", Value: "500", Literal: true},
+		{Line: 2, Column: 43, Scope: Global, Variable: "sort_buffer_size"},
+		{Line: 4, Column: 5, Scope: PersistOnly, Variable: "back_log", Value: "x;y", Literal: true, Quoted: true},
+		{Line: 6, Column: 5, Scope: Session, Implicit: true, Variable: "sql_mode"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Assignments() returned %d assignments, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Assignments()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSetStatements(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string // expected message, empty if valid
+	}{
+		{"SET GLOBAL max_connections = 500", ""},
+		{"SET GLOBAL max_connections = 0", "less than the minimum"},
+		{"SET GLOBAL max_connections = '500'", "expected a number"},
+		{"SET max_connections = 500", "should be set with SET GLOBAL"},
+		{"SET SESSION max_connections = 500", "can not be set with SET SESSION"},
+		{"SET GLOBAL back_log = 100", "not dynamic"},
+		{"SET PERSIST_ONLY back_log = 100", ""},
+		{"SET GLOBAL no_such_variable = 1", "unknown system variable"},
+		{"SET GLOBAL sql_log_bin = OFF", "is a SESSION variable"},
+		{"SET autocommit = maybe", "not a boolean"},
+		{"SET @@session.binlog_format = 'row'", ""},
+		{"SET @@global.binlog_format = 'BLOCK'", "expected one of"},
+		{"SET sql_mode = 'ANSI_QUOTES,NO_ZERO_DATE'", ""},
+		{"SET sql_mode = 'ANSI_QUOTES,NOPE'", "'NOPE' is not one of"},
+	}
+	for _, test := range tests {
+		problems, err := SetStatements(testTable(), strings.NewReader(test.sql))
+		if err != nil {
+			t.Fatalf("SetStatements(%q) failed: %v", test.sql, err)
+		}
+		switch {
+		case test.want == "" && len(problems) > 0:
+			t.Errorf("SetStatements(%q) = %v, want no problems", test.sql, problems)
+		case test.want != "" && (len(problems) != 1 || !strings.Contains(problems[0].Message, test.want)):
+			t.Errorf("SetStatements(%q) = %v, want %q", test.sql, problems, test.want)
+		}
+	}
+}
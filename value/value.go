@@ -0,0 +1,203 @@
+// Package value parses user supplied values for a system variable according
+// to the type and permitted values given in the documentation.
+package value
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+// The variable types found in the details tables
+const (
+	Integer       = "integer"
+	Numeric       = "numeric"
+	Boolean       = "boolean"
+	Enumeration   = "enumeration"
+	Set           = "set"
+	String        = "string"
+	FileName      = "file name"
+	DirectoryName = "directory name"
+)
+
+// TypeOf returns the type of the variable as one of the constants above.
+// The documentation isn't consistent about case, e.g. Integer or integer.
+func TypeOf(row table.Row) string {
+	return strings.ToLower(strings.TrimSpace(row.DataType()))
+}
+
+// Error describes a value which is not valid for a variable
+type Error struct {
+	Variable string
+	Value    string
+	Reason   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid value '%s' for %s: %s", e.Value, e.Variable, e.Reason)
+}
+
+// the multipliers of the size suffixes accepted by the server
+var suffixes = map[byte]uint{
+	'K': 10,
+	'M': 20,
+	'G': 30,
+	'T': 40,
+	'P': 50,
+	'E': 60,
+}
+
+// Parse parses a value as given on the command line or in an option file:
+// integers may have a size suffix such as K, M or G and booleans may be ON, OFF,
+// TRUE, FALSE, 1 or 0. The value returned depends on the type:
+//
+//	integer:     int64, or uint64 if too large for an int64
+//	numeric:     as integer, or float64 if it has a fraction
+//	boolean:     bool
+//	enumeration: string, using the case of the documented value
+//	set:         []string, using the case of the documented values
+//	otherwise:   string
+func Parse(row table.Row, s string) (interface{}, error) {
+	return parse(row, s, true)
+}
+
+// ParseSQL parses a value as given in a SET statement. Unlike Parse no size
+// suffixes are allowed but an enumeration may be given by its index.
+func ParseSQL(row table.Row, s string) (interface{}, error) {
+	return parse(row, s, false)
+}
+
+func parse(row table.Row, s string, optionFile bool) (interface{}, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return &Error{Variable: row.Name(), Value: s, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch TypeOf(row) {
+	case Integer, Numeric:
+		n, ok := parseInteger(s, optionFile)
+		if !ok {
+			if TypeOf(row) == Numeric {
+				if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+					if reason := checkRange(row, new(big.Float).SetFloat64(f)); reason != "" {
+						return nil, invalid("%s", reason)
+					}
+					if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+						return int64(f), nil
+					}
+					return f, nil
+				}
+			}
+			return nil, invalid("not an integer")
+		}
+		if reason := checkRange(row, new(big.Float).SetInt(n)); reason != "" {
+			return nil, invalid("%s", reason)
+		}
+		if n.IsInt64() {
+			return n.Int64(), nil
+		}
+		if n.IsUint64() {
+			return n.Uint64(), nil
+		}
+		return nil, invalid("out of range")
+	case Boolean:
+		switch strings.ToUpper(s) {
+		case "ON", "TRUE", "1":
+			return true, nil
+		case "OFF", "FALSE", "0":
+			return false, nil
+		}
+		return nil, invalid("not a boolean: expected ON, OFF, 1 or 0")
+	case Enumeration:
+		if v, ok := lookup(row.ValidValues(), s); ok {
+			return v, nil
+		}
+		if !optionFile {
+			if i, err := strconv.Atoi(s); err == nil && i >= 0 && i < len(row.ValidValues()) {
+				return row.ValidValues()[i], nil
+			}
+		}
+		return nil, invalid("expected one of %s", strings.Join(row.ValidValues(), ", "))
+	case Set:
+		values := []string{}
+		if s == "" {
+			return values, nil
+		}
+		for _, item := range strings.Split(s, ",") {
+			v, ok := lookup(row.ValidValues(), strings.TrimSpace(item))
+			if !ok {
+				return nil, invalid("'%s' is not one of %s", item, strings.Join(row.ValidValues(), ", "))
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	}
+	return s, nil
+}
+
+// parse an integer with an optional size suffix
+func parseInteger(s string, suffix bool) (*big.Int, bool) {
+	shift := uint(0)
+	if suffix && len(s) > 1 {
+		if bits, found := suffixes[strings.ToUpper(s[len(s)-1:])[0]]; found {
+			shift = bits
+			s = s[:len(s)-1]
+		}
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, false
+	}
+	return n.Lsh(n, shift), true
+}
+
+// check the value against the documented minimum and maximum returning
+// a reason if it is out of range. Limits which are not numbers are ignored.
+func checkRange(row table.Row, n *big.Float) string {
+	if min, _, err := big.ParseFloat(row.MinValue(), 10, 128, big.ToNearestEven); err == nil && n.Cmp(min) < 0 {
+		return "less than the minimum value " + row.MinValue()
+	}
+	if max, _, err := big.ParseFloat(row.MaxValue(), 10, 128, big.ToNearestEven); err == nil && n.Cmp(max) > 0 {
+		return "greater than the maximum value " + row.MaxValue()
+	}
+	return ""
+}
+
+// lookup a value ignoring case returning the documented form.
+// With no documented values we can't check so accept anything.
+func lookup(values []string, s string) (string, bool) {
+	if len(values) == 0 {
+		return s, true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Format returns a value returned by Parse in the form shown by SHOW VARIABLES
+func Format(v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return "ON"
+		}
+		return "OFF"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 6, 64)
+	case []string:
+		return strings.Join(v, ",")
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,119 @@
+package value
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sjmudd/mysql-variables-parser/table"
+)
+
+func newRow(dataType, min, max string, values ...string) table.Row {
+	var r table.Row
+	r.SetSystemVariableName("test_variable")
+	r.SetDataType(dataType)
+	r.SetMinValue(min)
+	r.SetMaxValue(max)
+	r.SetValidValues(values)
+	return r
+}
+
+func TestParse(t *testing.T) {
+	integer := newRow(Integer, "1024", "18446744073709551615")
+	numeric := newRow(Numeric, "0", "31536000")
+	boolean := newRow(Boolean, "", "")
+	enum := newRow(Enumeration, "", "", "ROW", "STATEMENT", "MIXED")
+	set := newRow(Set, "", "", "ANSI_QUOTES", "STRICT_TRANS_TABLES")
+	file := newRow(FileName, "", "")
+
+	tests := []struct {
+		row  table.Row
+		in   string
+		want interface{}
+	}{
+		{integer, "2048", int64(2048)},
+		{integer, "16k", int64(16384)},
+		{integer, "8M", int64(8 << 20)},
+		{integer, "1G", int64(1 << 30)},
+		{integer, "18446744073709551615", uint64(18446744073709551615)},
+		{numeric, "10", int64(10)},
+		{numeric, "10.000000", int64(10)},
+		{numeric, "0.5", 0.5},
+		{boolean, "on", true},
+		{boolean, "1", true},
+		{boolean, "OFF", false},
+		{boolean, "false", false},
+		{enum, "row", "ROW"},
+		{set, "strict_trans_tables,ANSI_QUOTES", []string{"STRICT_TRANS_TABLES", "ANSI_QUOTES"}},
+		{set, "", []string{}},
+		{file, "/var/lib/mysql/ib_logfile0", "/var/lib/mysql/ib_logfile0"},
+	}
+	for _, test := range tests {
+		got, err := Parse(test.row, test.in)
+		if err != nil {
+			t.Errorf("Parse(%s, %q) failed: %v", test.row.DataType(), test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%s, %q) = %#v, want %#v", test.row.DataType(), test.in, got, test.want)
+		}
+	}
+
+	invalid := []struct {
+		row table.Row
+		in  string
+	}{
+		{integer, "1000"},
+		{integer, "18446744073709551616"},
+		{integer, "lots"},
+		{numeric, "-1"},
+		{boolean, "yes"},
+		{enum, "BLOCK"},
+		{enum, "1"},
+		{set, "ANSI_QUOTES,NOPE"},
+	}
+	for _, test := range invalid {
+		if got, err := Parse(test.row, test.in); err == nil {
+			t.Errorf("Parse(%s, %q) = %#v, want an error", test.row.DataType(), test.in, got)
+		}
+	}
+}
+
+func TestParseSQL(t *testing.T) {
+	if _, err := ParseSQL(newRow(Integer, "", ""), "8M"); err == nil {
+		t.Errorf("ParseSQL() accepted a size suffix")
+	}
+	got, err := ParseSQL(newRow(Enumeration, "", "", "ROW", "STATEMENT", "MIXED"), "1")
+	if err != nil || got != "STATEMENT" {
+		t.Errorf("ParseSQL() of an enumeration index = %v, %v, want STATEMENT", got, err)
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	got, err := Parse(newRow("Integer", "0", "100"), "8")
+	if err != nil || got != int64(8) {
+		t.Errorf("Parse() of a documented Integer = %#v, %v, want 8", got, err)
+	}
+	if got := TypeOf(newRow(" File name ", "", "")); got != FileName {
+		t.Errorf("TypeOf() = %q, want %q", got, FileName)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{true, "ON"},
+		{false, "OFF"},
+		{int64(-1), "-1"},
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{0.5, "0.500000"},
+		{[]string{"A", "B"}, "A,B"},
+		{"x", "x"},
+	}
+	for _, test := range tests {
+		if got := Format(test.in); got != test.want {
+			t.Errorf("Format(%#v) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}